@@ -2,11 +2,33 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"vizdisk/internal/analyzer"
+	"vizdisk/internal/du"
+	"vizdisk/internal/export"
+	"vizdisk/internal/history"
 	"vizdisk/internal/models"
+	"vizdisk/internal/ncdu"
+	"vizdisk/internal/oci"
+	"vizdisk/internal/query"
+	"vizdisk/internal/report"
 	"vizdisk/internal/scanner"
+	"vizdisk/internal/search"
 	"vizdisk/internal/services"
+	"vizdisk/internal/snapshot"
+	"vizdisk/internal/treemap"
+	"vizdisk/internal/vfs"
 )
 
 // App struct
@@ -16,15 +38,29 @@ type App struct {
 	fileService     *services.FileService
 	platformService *services.PlatformService
 	dialogService   *services.DialogService
+	imageAnalyzer   *oci.Analyzer
+	fs              vfs.FS
+
+	mu               sync.Mutex
+	lastResult       *models.ScanResult
+	lastOptions      *scanner.ScanOptions
+	cancelAnalysis   context.CancelFunc
+	cleanupRulesPath string
+	searchIndex      *search.Index
+	historyOptions   *history.RecordOptions
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
+	fsys := vfs.NewOSFS()
 	return &App{
 		scanner:         scanner.NewScanner(scanner.DefaultScanOptions()),
 		fileService:     services.NewFileService(),
 		platformService: services.NewPlatformService(),
 		dialogService:   services.NewDialogService(),
+		imageAnalyzer:   oci.NewAnalyzer(fsys),
+		fs:              fsys,
+		historyOptions:  history.DefaultRecordOptions(),
 	}
 }
 
@@ -61,7 +97,689 @@ func (a *App) GetCommonDirectories() []string {
 
 // ScanDirectory scans a directory and returns the file tree
 func (a *App) ScanDirectory(path string) (*models.ScanResult, error) {
-	return a.scanner.ScanPath(path, nil)
+	a.mu.Lock()
+	s := a.scanner
+	a.mu.Unlock()
+
+	result, err := s.ScanPath(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	result.FileTypes = analyzer.NewFileTypeAnalyzer(a.fs, nil).Analyze(result.Root)
+
+	a.setResult(result, s.Options())
+	a.recordHistory(result)
+	return result, nil
+}
+
+// GetExpandArchives reports whether scans list the contents of zip, jar and
+// tar archives
+func (a *App) GetExpandArchives() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.scanner.Options().ExpandArchives
+}
+
+// SetExpandArchives changes whether later scans list the contents of
+// archives. The scanner is replaced rather than changed so that the options
+// kept for the last scan stay as they were
+func (a *App) SetExpandArchives(expand bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	options := *a.scanner.Options()
+	options.ExpandArchives = expand
+	a.scanner = scanner.NewScannerWithFS(a.fs, &options)
+}
+
+// setResult makes result the one analyses run against. options are those
+// the scan was made with, or nil for imported results.
+func (a *App) setResult(result *models.ScanResult, options *scanner.ScanOptions) {
+	a.mu.Lock()
+	a.lastResult = result
+	a.lastOptions = options
+	a.searchIndex = nil
+	a.mu.Unlock()
+}
+
+// currentResult returns the most recent scan, which analyses run against
+func (a *App) currentResult() (*models.ScanResult, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.lastResult == nil {
+		return nil, fmt.Errorf("no scan result available")
+	}
+	return a.lastResult, nil
+}
+
+// FindDuplicates searches the last scan for files with identical content.
+// Progress is emitted as "duplicates:progress" events
+func (a *App) FindDuplicates() (*analyzer.DuplicateReport, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+	return a.findDuplicates(result)
+}
+
+func (a *App) findDuplicates(result *models.ScanResult) (*analyzer.DuplicateReport, error) {
+	ctx, cancel := a.startAnalysis()
+	defer cancel()
+
+	finder := analyzer.NewDuplicateFinder(a.fs, analyzer.DefaultDuplicateOptions())
+	return finder.Find(ctx, result, func(progress *analyzer.DuplicateProgress) {
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "duplicates:progress", progress)
+		}
+	})
+}
+
+// FindDuplicateDirectories reports identical and near-identical directory
+// subtrees in the last scan. Progress of the underlying file comparison is
+// emitted as "duplicates:progress" events
+func (a *App) FindDuplicateDirectories() (*analyzer.DirectoryDuplicateReport, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := a.findDuplicates(result)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.FindDuplicateDirectories(result, files, analyzer.DefaultDirectoryDuplicateOptions()), nil
+}
+
+// GetFileTypeBreakdown aggregates the last scan by extension and category.
+// With sniffContent, files with unknown extensions are classified by their
+// first bytes
+func (a *App) GetFileTypeBreakdown(sniffContent bool) (*models.TypeBreakdown, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+
+	options := &analyzer.FileTypeOptions{SniffContent: sniffContent}
+	return analyzer.NewFileTypeAnalyzer(a.fs, options).Analyze(result.Root), nil
+}
+
+// GetAgeReport buckets the last scan by file age and lists the largest
+// subtrees untouched for staleAfterMonths months
+func (a *App) GetAgeReport(staleAfterMonths int) (*analyzer.AgeReport, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+
+	options := analyzer.DefaultAgeOptions()
+	if staleAfterMonths > 0 {
+		options.StaleAfterMonths = staleAfterMonths
+	}
+	return analyzer.AnalyzeAge(result.Root, options), nil
+}
+
+// GetOwnershipReport sums the last scan per owning user and group
+func (a *App) GetOwnershipReport() (*analyzer.OwnershipReport, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.AnalyzeOwnership(result.Root), nil
+}
+
+// FilterByOwner returns the last scan reduced to files owned by owner and,
+// if given, group
+func (a *App) FilterByOwner(owner, group string) (*models.ScanResult, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+	return scanner.NewResult(analyzer.FilterByOwner(result.Root, owner, group), result.ScanTime), nil
+}
+
+// GetTopN returns the largest files and directories of the last scan
+func (a *App) GetTopN(options analyzer.TopNOptions) (*analyzer.TopNResult, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.TopN(result.Root, &options), nil
+}
+
+// GetCleanupSuggestions lists regenerable directories such as dependency
+// folders and build caches found in the last scan
+func (a *App) GetCleanupSuggestions() (*analyzer.CleanupReport, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+	rules, ruleErrs := a.cleanupRules()
+	report := analyzer.NewCleanupAnalyzer(rules, nil).Analyze(result.Root)
+	report.RuleErrors = ruleErrs
+	return report, nil
+}
+
+// GetCleanupRulesPath returns the user cleanup rules file, which need not
+// exist yet
+func (a *App) GetCleanupRulesPath() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cleanupRulesPath != "" {
+		return a.cleanupRulesPath
+	}
+	configDir, err := a.platformService.GetConfigDirectory()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "cleanup-rules.yaml")
+}
+
+// SetCleanupRulesPath switches to another YAML or JSON rules file and returns
+// its validation errors
+func (a *App) SetCleanupRulesPath(path string) ([]*analyzer.RuleError, error) {
+	_, err := analyzer.LoadCleanupRules(a.fs, path)
+	var ruleErrs analyzer.RuleErrors
+	if err != nil && !errors.As(err, &ruleErrs) {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.cleanupRulesPath = path
+	a.mu.Unlock()
+	return ruleErrs, nil
+}
+
+// cleanupRules returns the user rules ahead of the built-in ones so that
+// they take precedence. A missing rules file is not an error.
+func (a *App) cleanupRules() ([]*analyzer.CleanupRule, analyzer.RuleErrors) {
+	rules := analyzer.DefaultCleanupRules()
+	path := a.GetCleanupRulesPath()
+	if path == "" {
+		return rules, nil
+	}
+
+	userRules, err := analyzer.LoadCleanupRules(a.fs, path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return rules, nil
+		}
+		var ruleErrs analyzer.RuleErrors
+		if !errors.As(err, &ruleErrs) {
+			ruleErrs = analyzer.RuleErrors{{Rule: -1, Message: err.Error()}}
+		}
+		return append(userRules, rules...), ruleErrs
+	}
+	return append(userRules, rules...), nil
+}
+
+// GetEmptyReport lists empty directories and zero-byte files in the last scan
+func (a *App) GetEmptyReport() (*analyzer.EmptyReport, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.FindEmpty(result.Root), nil
+}
+
+// RemoveEmpty deletes the given empty directories and zero-byte files after
+// checking each again on disk. Call it with dryRun first to list what would
+// be removed
+func (a *App) RemoveEmpty(paths []string, dryRun bool) *services.RemovalResult {
+	return a.fileService.RemoveEmpty(paths, dryRun)
+}
+
+// GetBrokenLinks lists dangling symlinks found by the last scan
+func (a *App) GetBrokenLinks() ([]*analyzer.BrokenLink, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.FindBrokenLinks(result.Root), nil
+}
+
+// FilterTree returns the last scan reduced to the files matching a query
+// language expression such as "files > 100MB, under ~/Projects"
+func (a *App) FilterTree(expression string) (*models.ScanResult, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := query.Compile(expression, nil)
+	if err != nil {
+		return nil, err
+	}
+	return scanner.NewResult(models.Filter(result.Root, match), result.ScanTime), nil
+}
+
+// SearchTree finds nodes of the last scan by name, path, size, date and type.
+// The index is built on the first search after each scan
+func (a *App) SearchTree(query search.Query) (*search.Results, error) {
+	a.mu.Lock()
+	if a.lastResult == nil {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("no scan result available")
+	}
+	if a.searchIndex == nil {
+		a.searchIndex = search.NewIndex(a.lastResult.Root)
+	}
+	idx := a.searchIndex
+	a.mu.Unlock()
+
+	return idx.Search(&query)
+}
+
+// GetTreemapLayout lays out the node with the given ID from the last scan,
+// or the root when nodeID is empty, returning only tiles large enough to draw
+func (a *App) GetTreemapLayout(nodeID string, options treemap.Options) ([]*treemap.Tile, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+
+	node := result.Root
+	if nodeID != "" {
+		if node = findNode(result.Root, nodeID); node == nil {
+			return nil, fmt.Errorf("node not found: %s", nodeID)
+		}
+	}
+	return treemap.Layout(node, &options), nil
+}
+
+func findNode(node *models.FileNode, id string) *models.FileNode {
+	if node.ID == id {
+		return node
+	}
+	for _, child := range node.Children {
+		if found := findNode(child, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// SaveSnapshot stores the last scan so that it can be reopened or compared
+// later. The scan options are recorded unless the scan was imported
+func (a *App) SaveSnapshot(label string) (*snapshot.Info, error) {
+	a.mu.Lock()
+	result, options := a.lastResult, a.lastOptions
+	a.mu.Unlock()
+	if result == nil {
+		return nil, fmt.Errorf("no scan result available")
+	}
+	store, err := a.snapshotStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.Save(result, options, label)
+}
+
+// ListSnapshots describes the saved snapshots, newest first
+func (a *App) ListSnapshots() ([]*snapshot.Info, error) {
+	store, err := a.snapshotStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.List()
+}
+
+// LoadSnapshot reopens a saved snapshot and makes it the current scan
+func (a *App) LoadSnapshot(id string) (*snapshot.Snapshot, error) {
+	store, err := a.snapshotStore()
+	if err != nil {
+		return nil, err
+	}
+	snap, err := store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	a.setResult(snap.Result, snap.Info.Options)
+	return snap, nil
+}
+
+// DeleteSnapshot removes a saved snapshot
+func (a *App) DeleteSnapshot(id string) error {
+	store, err := a.snapshotStore()
+	if err != nil {
+		return err
+	}
+	return store.Delete(id)
+}
+
+// CompareWithSnapshot reports what changed between a saved snapshot and the
+// last scan
+func (a *App) CompareWithSnapshot(id string, options analyzer.DiffOptions) (*analyzer.DiffReport, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return nil, err
+	}
+	store, err := a.snapshotStore()
+	if err != nil {
+		return nil, err
+	}
+	before, err := store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.Diff(before.Result, result, &options), nil
+}
+
+// CompareSnapshots reports what changed between two saved snapshots
+func (a *App) CompareSnapshots(beforeID, afterID string, options analyzer.DiffOptions) (*analyzer.DiffReport, error) {
+	store, err := a.snapshotStore()
+	if err != nil {
+		return nil, err
+	}
+	before, err := store.Load(beforeID)
+	if err != nil {
+		return nil, err
+	}
+	after, err := store.Load(afterID)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.Diff(before.Result, after.Result, &options), nil
+}
+
+func (a *App) snapshotStore() (*snapshot.Store, error) {
+	configDir, err := a.platformService.GetConfigDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.NewStore(filepath.Join(configDir, "snapshots")), nil
+}
+
+// recordHistory adds the directory sizes of a scan to the trend database.
+// The scan itself has succeeded, so failures are only logged.
+func (a *App) recordHistory(result *models.ScanResult) {
+	a.mu.Lock()
+	options := *a.historyOptions
+	a.mu.Unlock()
+
+	err := a.withHistory(func(db *history.DB) error {
+		_, err := db.Record(result, &options)
+		return err
+	})
+	if err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to record scan history: %v", err)
+	}
+}
+
+// GetHistorySeries returns the recorded sizes of a directory across scans,
+// oldest first, for charting its growth
+func (a *App) GetHistorySeries(path string) ([]*history.Point, error) {
+	var points []*history.Point
+	err := a.withHistory(func(db *history.DB) error {
+		var err error
+		points, err = db.Series(path, time.Time{}, time.Time{})
+		return err
+	})
+	return points, err
+}
+
+// ListHistoryScans describes the scans in the trend database, newest first
+func (a *App) ListHistoryScans() ([]*history.Scan, error) {
+	var scans []*history.Scan
+	err := a.withHistory(func(db *history.DB) error {
+		var err error
+		scans, err = db.Scans()
+		return err
+	})
+	return scans, err
+}
+
+// DeleteHistoryScan removes one scan from the trend database
+func (a *App) DeleteHistoryScan(id uint64) error {
+	return a.withHistory(func(db *history.DB) error {
+		return db.DeleteScan(id)
+	})
+}
+
+// GetHistoryDepth returns how many levels below the scanned root are recorded
+func (a *App) GetHistoryDepth() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.historyOptions.MaxDepth
+}
+
+// SetHistoryDepth changes how many levels below the scanned root later scans
+// record
+func (a *App) SetHistoryDepth(depth int) error {
+	if depth < 0 {
+		return fmt.Errorf("history depth must not be negative")
+	}
+	a.mu.Lock()
+	a.historyOptions.MaxDepth = depth
+	a.mu.Unlock()
+	return nil
+}
+
+// withHistory opens the trend database for the duration of fn, so that it
+// is not held locked while the app idles
+func (a *App) withHistory(fn func(*history.DB) error) error {
+	configDir, err := a.platformService.GetConfigDirectory()
+	if err != nil {
+		return err
+	}
+	db, err := history.Open(filepath.Join(configDir, "history.db"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return fn(db)
+}
+
+// ExportScan asks where to save the last scan and writes it as CSV, JSON or
+// NDJSON. It returns the chosen path, or an empty one if the user cancels.
+func (a *App) ExportScan(format string, options export.Options) (string, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return "", err
+	}
+
+	var filter runtime.FileFilter
+	switch format {
+	case export.FormatCSV:
+		filter = runtime.FileFilter{DisplayName: "CSV (*.csv)", Pattern: "*.csv"}
+	case export.FormatJSON:
+		filter = runtime.FileFilter{DisplayName: "JSON (*.json)", Pattern: "*.json"}
+	case export.FormatNDJSON:
+		filter = runtime.FileFilter{DisplayName: "NDJSON (*.ndjson)", Pattern: "*.ndjson;*.jsonl"}
+	default:
+		return "", fmt.Errorf("unknown export format: %s", format)
+	}
+
+	path, err := a.dialogService.SaveFileDialog("vizdisk-export."+format, []runtime.FileFilter{filter})
+	if err != nil || path == "" {
+		return "", err
+	}
+	return path, writeFile(path, func(w io.Writer) error {
+		return export.Write(w, result, format, &options)
+	})
+}
+
+// ImportNcdu opens an `ncdu -o` export and makes it the current scan. An
+// empty path asks for the file first; cancelling returns no result.
+func (a *App) ImportNcdu(path string) (*models.ScanResult, error) {
+	if path == "" {
+		var err error
+		path, err = a.dialogService.OpenFileDialog([]runtime.FileFilter{
+			{DisplayName: "ncdu export (*.json)", Pattern: "*.json;*.ncdu"},
+		})
+		if err != nil || path == "" {
+			return nil, err
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result, err := ncdu.Read(f)
+	if err != nil {
+		return nil, err
+	}
+	result.FileTypes = analyzer.NewFileTypeAnalyzer(a.fs, nil).Analyze(result.Root)
+	a.setResult(result, nil)
+	return result, nil
+}
+
+// ImportDu reads saved `du -a` output and makes it the current scan. An
+// empty path asks for the file first; cancelling returns no result.
+func (a *App) ImportDu(path string, options du.Options) (*models.ScanResult, error) {
+	if path == "" {
+		var err error
+		path, err = a.dialogService.OpenFileDialog([]runtime.FileFilter{
+			{DisplayName: "du output (*.txt, *.du)", Pattern: "*.txt;*.du;*.log"},
+		})
+		if err != nil || path == "" {
+			return nil, err
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result, err := du.Parse(f, &options)
+	if err != nil {
+		return nil, err
+	}
+	result.FileTypes = analyzer.NewFileTypeAnalyzer(a.fs, nil).Analyze(result.Root)
+	a.setResult(result, nil)
+	return result, nil
+}
+
+// ExportNcdu asks where to save the last scan in ncdu's export format, which
+// `ncdu -f` can browse. It returns the chosen path, or an empty one if the
+// user cancels.
+func (a *App) ExportNcdu() (string, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return "", err
+	}
+	path, err := a.dialogService.SaveFileDialog("vizdisk-ncdu.json", []runtime.FileFilter{
+		{DisplayName: "ncdu export (*.json)", Pattern: "*.json"},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	info := a.GetAppInfo()
+	return path, writeFile(path, func(w io.Writer) error {
+		return ncdu.Write(w, result, "vizdisk", info["version"])
+	})
+}
+
+// ExportHTMLReport saves a self-contained HTML report of the current scan,
+// which opens in any browser without VizDisk or network access
+func (a *App) ExportHTMLReport(options report.Options) (string, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return "", err
+	}
+	path, err := a.dialogService.SaveFileDialog("vizdisk-report.html", []runtime.FileFilter{
+		{DisplayName: "HTML report (*.html)", Pattern: "*.html"},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	// Invalid user rules are reported by GetCleanupSuggestions; the valid
+	// ones still apply here
+	options.CleanupRules, _ = a.cleanupRules()
+	return path, writeFile(path, func(w io.Writer) error {
+		return report.HTML(w, result, &options)
+	})
+}
+
+// ExportMarkdownReport saves a Markdown summary of the current scan. When
+// previousSnapshotID is set, the report also lists what changed since that
+// snapshot.
+func (a *App) ExportMarkdownReport(previousSnapshotID string, options report.Options) (string, error) {
+	result, err := a.currentResult()
+	if err != nil {
+		return "", err
+	}
+	var previous *models.ScanResult
+	if previousSnapshotID != "" {
+		store, err := a.snapshotStore()
+		if err != nil {
+			return "", err
+		}
+		snap, err := store.Load(previousSnapshotID)
+		if err != nil {
+			return "", err
+		}
+		previous = snap.Result
+	}
+	path, err := a.dialogService.SaveFileDialog("vizdisk-report.md", []runtime.FileFilter{
+		{DisplayName: "Markdown (*.md)", Pattern: "*.md"},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	options.CleanupRules, _ = a.cleanupRules()
+	return path, writeFile(path, func(w io.Writer) error {
+		return report.Markdown(w, result, previous, &options)
+	})
+}
+
+// writeFile creates path and removes it again if writing fails, so that no
+// truncated exports are left behind
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// CancelAnalysis stops a running long analysis such as FindDuplicates
+func (a *App) CancelAnalysis() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cancelAnalysis != nil {
+		a.cancelAnalysis()
+	}
+}
+
+// startAnalysis cancels any analysis still in flight and returns a context
+// for a new one
+func (a *App) startAnalysis() (context.Context, context.CancelFunc) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cancelAnalysis != nil {
+		a.cancelAnalysis()
+	}
+
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	a.cancelAnalysis = cancel
+	return ctx, cancel
+}
+
+// AnalyzeContainerImage reads an OCI image layout directory or a docker-save
+// tarball and returns the merged filesystem grouped by contributing layer
+func (a *App) AnalyzeContainerImage(path string) (*oci.Image, error) {
+	image, err := a.imageAnalyzer.Analyze(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze image: %w", err)
+	}
+	return image, nil
 }
 
 // GetDirectoryInfo returns basic information about a directory
@@ -118,10 +836,64 @@ func (a *App) Greet(name string) string {
 // DeletePath deletes a file or directory
 // Note: Confirmation should be handled by the frontend
 func (a *App) DeletePath(path string) error {
+	if err := a.checkOnDisk(path); err != nil {
+		return err
+	}
 	return a.fileService.DeletePath(path)
 }
 
 // OpenInFinder opens a file or directory in the system file manager
 func (a *App) OpenInFinder(path string) error {
+	if err := a.checkOnDisk(path); err != nil {
+		return err
+	}
 	return a.platformService.OpenInFileManager(path)
 }
+
+// checkOnDisk refuses paths that the last scan found inside an archive. They
+// do not exist on disk, and acting on them would reach whatever real path
+// they happen to resolve to.
+func (a *App) checkOnDisk(path string) error {
+	a.mu.Lock()
+	result := a.lastResult
+	a.mu.Unlock()
+
+	if result != nil && inArchive(result.Root, filepath.Clean(path)) {
+		return fmt.Errorf("%s is inside an archive and does not exist on disk", path)
+	}
+	return nil
+}
+
+// inArchive reports whether path is a virtual node of the tree or lies below
+// a file, such as an expanded archive, of the tree.
+func inArchive(root *models.FileNode, path string) bool {
+	if root == nil || (root.Path != path && !isBelow(path, root.Path)) {
+		return false
+	}
+	for node := root; node != nil; {
+		if node.IsVirtual {
+			return true
+		}
+		if node.Path == path {
+			return false
+		}
+		if node.Type == scanner.FileTypeFile {
+			return true
+		}
+
+		var next *models.FileNode
+		for _, child := range node.Children {
+			if child.Path == path || isBelow(path, child.Path) {
+				next = child
+				break
+			}
+		}
+		node = next
+	}
+	return false
+}
+
+// isBelow reports whether path lies strictly below dir.
+func isBelow(path, dir string) bool {
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
@@ -0,0 +1,81 @@
+// Command vizdisk-render scans a directory and writes a treemap or sunburst
+// picture of it without starting the GUI.
+//
+//	vizdisk-render -o usage.png -chart sunburst ~/Projects
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/render"
+	"vizdisk/internal/scanner"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		// -h has already printed the usage
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "vizdisk-render:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	defaults := render.DefaultOptions()
+	flags := flag.NewFlagSet("vizdisk-render", flag.ContinueOnError)
+	output := flags.String("o", "", "output file, .svg or .png")
+	chart := flags.String("chart", defaults.Chart, "chart type: treemap or sunburst")
+	width := flags.Int("width", defaults.Width, "image width in pixels")
+	height := flags.Int("height", defaults.Height, "image height in pixels")
+	depth := flags.Int("depth", defaults.MaxDepth, "directory levels to draw")
+	title := flags.String("title", "", "title drawn above the chart")
+	hidden := flags.Bool("hidden", false, "include hidden files")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: vizdisk-render -o FILE [flags] PATH")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("an output file and one path are required")
+	}
+
+	var encode func(io.Writer, *models.ScanResult, *render.Options) error
+	switch strings.ToLower(filepath.Ext(*output)) {
+	case ".svg":
+		encode = render.SVG
+	case ".png":
+		encode = render.PNG
+	default:
+		return fmt.Errorf("unsupported output format: %s", *output)
+	}
+
+	scanOptions := scanner.DefaultScanOptions()
+	scanOptions.ShowHiddenFiles = *hidden
+	result, err := scanner.NewScanner(scanOptions).ScanPath(flags.Arg(0), nil)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	options := &render.Options{Chart: *chart, Width: *width, Height: *height, MaxDepth: *depth, Title: *title}
+	if err := encode(f, result, options); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
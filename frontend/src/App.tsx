@@ -15,7 +15,13 @@ import { ToggleGroup, ToggleGroupItem } from '@/components/ui/toggle-group';
 import { useVisualizationSettings } from '@/hooks/useVisualizationSettings';
 import { calculateNodeStats } from '@/utils/fileOperations';
 import { formatDuration, formatFileSize } from '@/utils/formatters';
-import { GetAppInfo, OpenDirectoryDialog, ScanDirectory } from '../wailsjs/go/main/App';
+import {
+  GetAppInfo,
+  GetExpandArchives,
+  OpenDirectoryDialog,
+  ScanDirectory,
+  SetExpandArchives,
+} from '../wailsjs/go/main/App';
 import type { models } from '../wailsjs/go/models';
 import { BrowserOpenURL, Quit, WindowMinimise } from '../wailsjs/runtime/runtime';
 import SunburstChart from './components/charts/SunburstChart';
@@ -30,6 +36,7 @@ function App() {
   const [breadcrumbs, setBreadcrumbs] = useState<models.FileNode[]>([]);
   const [appInfo, setAppInfo] = useState<{ [key: string]: string }>({});
   const [showShortcuts, setShowShortcuts] = useState(false);
+  const [expandArchives, setExpandArchives] = useState(false);
   const { visualizationType, setVisualizationType } = useVisualizationSettings();
 
   const loadAppInfo = async () => {
@@ -41,6 +48,24 @@ function App() {
     }
   };
 
+  const loadScanOptions = async () => {
+    try {
+      setExpandArchives(await GetExpandArchives());
+    } catch (error) {
+      console.error('Failed to load scan options:', error);
+    }
+  };
+
+  const handleExpandArchivesChange = async (expand: boolean) => {
+    setExpandArchives(expand);
+    try {
+      await SetExpandArchives(expand);
+    } catch (error) {
+      console.error('Failed to change scan options:', error);
+      setExpandArchives(!expand);
+    }
+  };
+
   const handleFolderPicker = React.useCallback(async () => {
     try {
       const selectedPath = await OpenDirectoryDialog();
@@ -88,6 +113,7 @@ function App() {
 
   useEffect(() => {
     loadAppInfo();
+    loadScanOptions();
   }, []);
 
   useEffect(() => {
@@ -144,7 +170,7 @@ function App() {
 
       <main className="container mx-auto px-8 py-6 max-w-6xl flex-1 flex flex-col overflow-hidden min-h-0">
         {!isScanning && (
-          <div className="flex items-center justify-center">
+          <div className="flex flex-col items-center justify-center space-y-3">
             <div className="flex items-center space-x-4 w-full max-w-2xl">
               <Input
                 value={currentPath}
@@ -163,6 +189,15 @@ function App() {
                 {isScanning ? 'Scanning...' : 'Analyze'}
               </Button>
             </div>
+            <label className="flex items-center space-x-2 w-full max-w-2xl text-sm text-muted-foreground">
+              <input
+                type="checkbox"
+                checked={expandArchives}
+                onChange={(e) => handleExpandArchivesChange(e.target.checked)}
+                className="h-4 w-4 accent-primary"
+              />
+              <span>Look inside zip, jar and tar archives</span>
+            </label>
           </div>
         )}
 
@@ -30,12 +30,13 @@ export const TreeMapContextMenu: React.FC<TreeMapContextMenuProps> = ({
     <ContextMenu>
       <ContextMenuTrigger asChild>{children}</ContextMenuTrigger>
       <ContextMenuContent className="w-56">
-        <ContextMenuItem onClick={() => onOpenInFinder(node)}>
+        <ContextMenuItem disabled={node.isVirtual} onClick={() => onOpenInFinder(node)}>
           <FolderOpen className="mr-2 h-4 w-4" />
           <span>Show in Finder</span>
         </ContextMenuItem>
         <ContextMenuSeparator />
         <ContextMenuItem
+          disabled={node.isVirtual}
           onClick={() => onDelete(node)}
           className="text-destructive focus:text-destructive"
         >
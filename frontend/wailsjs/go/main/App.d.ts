@@ -1,23 +1,107 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {oci} from '../models';
+import {analyzer} from '../models';
+import {report} from '../models';
+import {export} from '../models';
 import {models} from '../models';
+import {history} from '../models';
+import {treemap} from '../models';
+import {du} from '../models';
+import {snapshot} from '../models';
+import {services} from '../models';
+import {search} from '../models';
+
+export function AnalyzeContainerImage(arg1:string):Promise<oci.Image>;
+
+export function CancelAnalysis():Promise<void>;
+
+export function CompareSnapshots(arg1:string,arg2:string,arg3:analyzer.DiffOptions):Promise<analyzer.DiffReport>;
+
+export function CompareWithSnapshot(arg1:string,arg2:analyzer.DiffOptions):Promise<analyzer.DiffReport>;
+
+export function DeleteHistoryScan(arg1:number):Promise<void>;
 
 export function DeletePath(arg1:string):Promise<void>;
 
+export function DeleteSnapshot(arg1:string):Promise<void>;
+
+export function ExportHTMLReport(arg1:report.Options):Promise<string>;
+
+export function ExportMarkdownReport(arg1:string,arg2:report.Options):Promise<string>;
+
+export function ExportNcdu():Promise<string>;
+
+export function ExportScan(arg1:string,arg2:export.Options):Promise<string>;
+
+export function FilterByOwner(arg1:string,arg2:string):Promise<models.ScanResult>;
+
+export function FilterTree(arg1:string):Promise<models.ScanResult>;
+
+export function FindDuplicateDirectories():Promise<analyzer.DirectoryDuplicateReport>;
+
+export function FindDuplicates():Promise<analyzer.DuplicateReport>;
+
+export function GetAgeReport(arg1:number):Promise<analyzer.AgeReport>;
+
 export function GetAppInfo():Promise<Record<string, string>>;
 
+export function GetBrokenLinks():Promise<Array<analyzer.BrokenLink>>;
+
+export function GetCleanupRulesPath():Promise<string>;
+
+export function GetCleanupSuggestions():Promise<analyzer.CleanupReport>;
+
 export function GetCommonDirectories():Promise<Array<string>>;
 
 export function GetDirectoryInfo(arg1:string):Promise<models.FileNode>;
 
+export function GetEmptyReport():Promise<analyzer.EmptyReport>;
+
+export function GetExpandArchives():Promise<boolean>;
+
+export function GetFileTypeBreakdown(arg1:boolean):Promise<models.TypeBreakdown>;
+
+export function GetHistoryDepth():Promise<number>;
+
+export function GetHistorySeries(arg1:string):Promise<Array<history.Point>>;
+
+export function GetOwnershipReport():Promise<analyzer.OwnershipReport>;
+
+export function GetTopN(arg1:analyzer.TopNOptions):Promise<analyzer.TopNResult>;
+
+export function GetTreemapLayout(arg1:string,arg2:treemap.Options):Promise<Array<treemap.Tile>>;
+
 export function GetUserHomeDirectory():Promise<string>;
 
 export function Greet(arg1:string):Promise<string>;
 
+export function ImportDu(arg1:string,arg2:du.Options):Promise<models.ScanResult>;
+
+export function ImportNcdu(arg1:string):Promise<models.ScanResult>;
+
+export function ListHistoryScans():Promise<Array<history.Scan>>;
+
+export function ListSnapshots():Promise<Array<snapshot.Info>>;
+
+export function LoadSnapshot(arg1:string):Promise<snapshot.Snapshot>;
+
 export function OpenDirectoryDialog():Promise<string>;
 
 export function OpenInFinder(arg1:string):Promise<void>;
 
+export function RemoveEmpty(arg1:Array<string>,arg2:boolean):Promise<services.RemovalResult>;
+
+export function SaveSnapshot(arg1:string):Promise<snapshot.Info>;
+
 export function ScanDirectory(arg1:string):Promise<models.ScanResult>;
 
+export function SearchTree(arg1:search.Query):Promise<search.Results>;
+
+export function SetCleanupRulesPath(arg1:string):Promise<Array<analyzer.RuleError>>;
+
+export function SetExpandArchives(arg1:boolean):Promise<void>;
+
+export function SetHistoryDepth(arg1:number):Promise<void>;
+
 export function ValidatePath(arg1:string):Promise<boolean>;
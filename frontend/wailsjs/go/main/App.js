@@ -2,14 +2,86 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AnalyzeContainerImage(arg1) {
+  return window['go']['main']['App']['AnalyzeContainerImage'](arg1);
+}
+
+export function CancelAnalysis() {
+  return window['go']['main']['App']['CancelAnalysis']();
+}
+
+export function CompareSnapshots(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CompareSnapshots'](arg1, arg2, arg3);
+}
+
+export function CompareWithSnapshot(arg1, arg2) {
+  return window['go']['main']['App']['CompareWithSnapshot'](arg1, arg2);
+}
+
+export function DeleteHistoryScan(arg1) {
+  return window['go']['main']['App']['DeleteHistoryScan'](arg1);
+}
+
 export function DeletePath(arg1) {
   return window['go']['main']['App']['DeletePath'](arg1);
 }
 
+export function DeleteSnapshot(arg1) {
+  return window['go']['main']['App']['DeleteSnapshot'](arg1);
+}
+
+export function ExportHTMLReport(arg1) {
+  return window['go']['main']['App']['ExportHTMLReport'](arg1);
+}
+
+export function ExportMarkdownReport(arg1, arg2) {
+  return window['go']['main']['App']['ExportMarkdownReport'](arg1, arg2);
+}
+
+export function ExportNcdu() {
+  return window['go']['main']['App']['ExportNcdu']();
+}
+
+export function ExportScan(arg1, arg2) {
+  return window['go']['main']['App']['ExportScan'](arg1, arg2);
+}
+
+export function FilterByOwner(arg1, arg2) {
+  return window['go']['main']['App']['FilterByOwner'](arg1, arg2);
+}
+
+export function FilterTree(arg1) {
+  return window['go']['main']['App']['FilterTree'](arg1);
+}
+
+export function FindDuplicateDirectories() {
+  return window['go']['main']['App']['FindDuplicateDirectories']();
+}
+
+export function FindDuplicates() {
+  return window['go']['main']['App']['FindDuplicates']();
+}
+
+export function GetAgeReport(arg1) {
+  return window['go']['main']['App']['GetAgeReport'](arg1);
+}
+
 export function GetAppInfo() {
   return window['go']['main']['App']['GetAppInfo']();
 }
 
+export function GetBrokenLinks() {
+  return window['go']['main']['App']['GetBrokenLinks']();
+}
+
+export function GetCleanupRulesPath() {
+  return window['go']['main']['App']['GetCleanupRulesPath']();
+}
+
+export function GetCleanupSuggestions() {
+  return window['go']['main']['App']['GetCleanupSuggestions']();
+}
+
 export function GetCommonDirectories() {
   return window['go']['main']['App']['GetCommonDirectories']();
 }
@@ -18,6 +90,38 @@ export function GetDirectoryInfo(arg1) {
   return window['go']['main']['App']['GetDirectoryInfo'](arg1);
 }
 
+export function GetEmptyReport() {
+  return window['go']['main']['App']['GetEmptyReport']();
+}
+
+export function GetExpandArchives() {
+  return window['go']['main']['App']['GetExpandArchives']();
+}
+
+export function GetFileTypeBreakdown(arg1) {
+  return window['go']['main']['App']['GetFileTypeBreakdown'](arg1);
+}
+
+export function GetHistoryDepth() {
+  return window['go']['main']['App']['GetHistoryDepth']();
+}
+
+export function GetHistorySeries(arg1) {
+  return window['go']['main']['App']['GetHistorySeries'](arg1);
+}
+
+export function GetOwnershipReport() {
+  return window['go']['main']['App']['GetOwnershipReport']();
+}
+
+export function GetTopN(arg1) {
+  return window['go']['main']['App']['GetTopN'](arg1);
+}
+
+export function GetTreemapLayout(arg1, arg2) {
+  return window['go']['main']['App']['GetTreemapLayout'](arg1, arg2);
+}
+
 export function GetUserHomeDirectory() {
   return window['go']['main']['App']['GetUserHomeDirectory']();
 }
@@ -26,6 +130,26 @@ export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function ImportDu(arg1, arg2) {
+  return window['go']['main']['App']['ImportDu'](arg1, arg2);
+}
+
+export function ImportNcdu(arg1) {
+  return window['go']['main']['App']['ImportNcdu'](arg1);
+}
+
+export function ListHistoryScans() {
+  return window['go']['main']['App']['ListHistoryScans']();
+}
+
+export function ListSnapshots() {
+  return window['go']['main']['App']['ListSnapshots']();
+}
+
+export function LoadSnapshot(arg1) {
+  return window['go']['main']['App']['LoadSnapshot'](arg1);
+}
+
 export function OpenDirectoryDialog() {
   return window['go']['main']['App']['OpenDirectoryDialog']();
 }
@@ -34,10 +158,34 @@ export function OpenInFinder(arg1) {
   return window['go']['main']['App']['OpenInFinder'](arg1);
 }
 
+export function RemoveEmpty(arg1, arg2) {
+  return window['go']['main']['App']['RemoveEmpty'](arg1, arg2);
+}
+
+export function SaveSnapshot(arg1) {
+  return window['go']['main']['App']['SaveSnapshot'](arg1);
+}
+
 export function ScanDirectory(arg1) {
   return window['go']['main']['App']['ScanDirectory'](arg1);
 }
 
+export function SearchTree(arg1) {
+  return window['go']['main']['App']['SearchTree'](arg1);
+}
+
+export function SetCleanupRulesPath(arg1) {
+  return window['go']['main']['App']['SetCleanupRulesPath'](arg1);
+}
+
+export function SetExpandArchives(arg1) {
+  return window['go']['main']['App']['SetExpandArchives'](arg1);
+}
+
+export function SetHistoryDepth(arg1) {
+  return window['go']['main']['App']['SetHistoryDepth'](arg1);
+}
+
 export function ValidatePath(arg1) {
   return window['go']['main']['App']['ValidatePath'](arg1);
 }
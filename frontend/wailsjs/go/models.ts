@@ -1,19 +1,1095 @@
+export namespace analyzer {
+	
+	export class AgeBucket {
+	    label: string;
+	    minDays: number;
+	    maxDays: number;
+	    size: number;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AgeBucket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.label = source["label"];
+	        this.minDays = source["minDays"];
+	        this.maxDays = source["maxDays"];
+	        this.size = source["size"];
+	        this.count = source["count"];
+	    }
+	}
+	export class StaleEntry {
+	    path: string;
+	    type: string;
+	    size: number;
+	    // Go type: time
+	    lastTouched: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new StaleEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.type = source["type"];
+	        this.size = source["size"];
+	        this.lastTouched = this.convertValues(source["lastTouched"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AgeReport {
+	    modified: AgeBucket[];
+	    accessed: AgeBucket[];
+	    stale: StaleEntry[];
+	    // Go type: time
+	    cutoff: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new AgeReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.modified = this.convertValues(source["modified"], AgeBucket);
+	        this.accessed = this.convertValues(source["accessed"], AgeBucket);
+	        this.stale = this.convertValues(source["stale"], StaleEntry);
+	        this.cutoff = this.convertValues(source["cutoff"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BrokenLink {
+	    path: string;
+	    target: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BrokenLink(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.target = source["target"];
+	    }
+	}
+	export class CleanupCandidate {
+	    path: string;
+	    ruleId: string;
+	    description: string;
+	    size: number;
+	    // Go type: time
+	    lastUsed: any;
+	    safety: string;
+	    action: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupCandidate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.ruleId = source["ruleId"];
+	        this.description = source["description"];
+	        this.size = source["size"];
+	        this.lastUsed = this.convertValues(source["lastUsed"], null);
+	        this.safety = source["safety"];
+	        this.action = source["action"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RuleError {
+	    rule: number;
+	    id?: string;
+	    field?: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rule = source["rule"];
+	        this.id = source["id"];
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
+	export class CleanupReport {
+	    candidates: CleanupCandidate[];
+	    totalSize: number;
+	    ruleErrors?: RuleError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.candidates = this.convertValues(source["candidates"], CleanupCandidate);
+	        this.totalSize = source["totalSize"];
+	        this.ruleErrors = this.convertValues(source["ruleErrors"], RuleError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CleanupRule {
+	    id: string;
+	    description: string;
+	    names?: string[];
+	    pathSuffixes?: string[];
+	    markers?: string[];
+	    safety: string;
+	    minAgeDays?: number;
+	    action?: string;
+	    where?: string;
+	    descend?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.description = source["description"];
+	        this.names = source["names"];
+	        this.pathSuffixes = source["pathSuffixes"];
+	        this.markers = source["markers"];
+	        this.safety = source["safety"];
+	        this.minAgeDays = source["minAgeDays"];
+	        this.action = source["action"];
+	        this.where = source["where"];
+	        this.descend = source["descend"];
+	    }
+	}
+	export class DeltaNode {
+	    id: string;
+	    name: string;
+	    path: string;
+	    type: string;
+	    status: string;
+	    oldSize: number;
+	    newSize: number;
+	    delta: number;
+	    added: number;
+	    removed: number;
+	    grown: number;
+	    shrunk: number;
+	    children?: DeltaNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DeltaNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.type = source["type"];
+	        this.status = source["status"];
+	        this.oldSize = source["oldSize"];
+	        this.newSize = source["newSize"];
+	        this.delta = source["delta"];
+	        this.added = source["added"];
+	        this.removed = source["removed"];
+	        this.grown = source["grown"];
+	        this.shrunk = source["shrunk"];
+	        this.children = this.convertValues(source["children"], DeltaNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DiffOptions {
+	    includeUnchanged: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.includeUnchanged = source["includeUnchanged"];
+	    }
+	}
+	export class DiffReport {
+	    root?: DeltaNode;
+	    // Go type: time
+	    oldScanTime: any;
+	    // Go type: time
+	    newScanTime: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = this.convertValues(source["root"], DeltaNode);
+	        this.oldScanTime = this.convertValues(source["oldScanTime"], null);
+	        this.newScanTime = this.convertValues(source["newScanTime"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DirectoryMatch {
+	    pathA: string;
+	    pathB: string;
+	    sizeA: number;
+	    sizeB: number;
+	    sharedBytes: number;
+	    similarity: number;
+	    identical: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DirectoryMatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pathA = source["pathA"];
+	        this.pathB = source["pathB"];
+	        this.sizeA = source["sizeA"];
+	        this.sizeB = source["sizeB"];
+	        this.sharedBytes = source["sharedBytes"];
+	        this.similarity = source["similarity"];
+	        this.identical = source["identical"];
+	    }
+	}
+	export class DirectoryDuplicateReport {
+	    matches: DirectoryMatch[];
+	    reclaimableBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DirectoryDuplicateReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.matches = this.convertValues(source["matches"], DirectoryMatch);
+	        this.reclaimableBytes = source["reclaimableBytes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class DuplicateGroup {
+	    hash: string;
+	    size: number;
+	    paths: string[];
+	    reclaimableBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DuplicateGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hash = source["hash"];
+	        this.size = source["size"];
+	        this.paths = source["paths"];
+	        this.reclaimableBytes = source["reclaimableBytes"];
+	    }
+	}
+	export class DuplicateReport {
+	    groups: DuplicateGroup[];
+	    totalReclaimable: number;
+	    filesCompared: number;
+	    bytesHashed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DuplicateReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.groups = this.convertValues(source["groups"], DuplicateGroup);
+	        this.totalReclaimable = source["totalReclaimable"];
+	        this.filesCompared = source["filesCompared"];
+	        this.bytesHashed = source["bytesHashed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EmptyDirectory {
+	    path: string;
+	    nested: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EmptyDirectory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.nested = source["nested"];
+	    }
+	}
+	export class EmptyReport {
+	    directories: EmptyDirectory[];
+	    files: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new EmptyReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.directories = this.convertValues(source["directories"], EmptyDirectory);
+	        this.files = source["files"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OwnerStat {
+	    name: string;
+	    size: number;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OwnerStat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.count = source["count"];
+	    }
+	}
+	export class OwnershipReport {
+	    users: OwnerStat[];
+	    groups: OwnerStat[];
+	
+	    static createFrom(source: any = {}) {
+	        return new OwnershipReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.users = this.convertValues(source["users"], OwnerStat);
+	        this.groups = this.convertValues(source["groups"], OwnerStat);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RankedNode {
+	    path: string;
+	    name: string;
+	    type: string;
+	    size: number;
+	    // Go type: time
+	    lastModified: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new RankedNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.size = source["size"];
+	        this.lastModified = this.convertValues(source["lastModified"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class TopNOptions {
+	    n: number;
+	    directorySize: string;
+	    extensions: string[];
+	    // Go type: time
+	    modifiedBefore: any;
+	    // Go type: time
+	    modifiedAfter: any;
+	    pathPrefix: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TopNOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.n = source["n"];
+	        this.directorySize = source["directorySize"];
+	        this.extensions = source["extensions"];
+	        this.modifiedBefore = this.convertValues(source["modifiedBefore"], null);
+	        this.modifiedAfter = this.convertValues(source["modifiedAfter"], null);
+	        this.pathPrefix = source["pathPrefix"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TopNResult {
+	    files: RankedNode[];
+	    directories: RankedNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TopNResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], RankedNode);
+	        this.directories = this.convertValues(source["directories"], RankedNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace du {
+	
+	export class Options {
+	    blockSize: number;
+	    root: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Options(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.blockSize = source["blockSize"];
+	        this.root = source["root"];
+	    }
+	}
+
+}
+
+export namespace export {
+	
+	export class Options {
+	    maxDepth: number;
+	    minSize: number;
+	    filter: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Options(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxDepth = source["maxDepth"];
+	        this.minSize = source["minSize"];
+	        this.filter = source["filter"];
+	    }
+	}
+
+}
+
+export namespace history {
+	
+	export class Point {
+	    scanId: number;
+	    // Go type: time
+	    time: any;
+	    size: number;
+	    files: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Point(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.scanId = source["scanId"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.size = source["size"];
+	        this.files = source["files"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Scan {
+	    id: number;
+	    path: string;
+	    // Go type: time
+	    scanTime: any;
+	    totalSize: number;
+	    totalFiles: number;
+	    maxDepth: number;
+	    directories: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Scan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.path = source["path"];
+	        this.scanTime = this.convertValues(source["scanTime"], null);
+	        this.totalSize = source["totalSize"];
+	        this.totalFiles = source["totalFiles"];
+	        this.maxDepth = source["maxDepth"];
+	        this.directories = source["directories"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace models {
 	
-	export class FileNode {
+	export class FileNode {
+	    id: string;
+	    name: string;
+	    path: string;
+	    size: number;
+	    type: string;
+	    children?: FileNode[];
+	    // Go type: time
+	    lastModified: any;
+	    // Go type: time
+	    lastAccessed: any;
+	    isHidden: boolean;
+	    permissions?: string;
+	    owner?: string;
+	    group?: string;
+	    isVirtual?: boolean;
+	    compressedSize?: number;
+	    isBrokenLink?: boolean;
+	    linkTarget?: string;
+	    allocatedSize?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.type = source["type"];
+	        this.children = this.convertValues(source["children"], FileNode);
+	        this.lastModified = this.convertValues(source["lastModified"], null);
+	        this.lastAccessed = this.convertValues(source["lastAccessed"], null);
+	        this.isHidden = source["isHidden"];
+	        this.permissions = source["permissions"];
+	        this.owner = source["owner"];
+	        this.group = source["group"];
+	        this.isVirtual = source["isVirtual"];
+	        this.compressedSize = source["compressedSize"];
+	        this.isBrokenLink = source["isBrokenLink"];
+	        this.linkTarget = source["linkTarget"];
+	        this.allocatedSize = source["allocatedSize"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TypeStat {
+	    key: string;
+	    size: number;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TypeStat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.size = source["size"];
+	        this.count = source["count"];
+	    }
+	}
+	export class TypeBreakdown {
+	    extensions: TypeStat[];
+	    categories: TypeStat[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TypeBreakdown(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.extensions = this.convertValues(source["extensions"], TypeStat);
+	        this.categories = this.convertValues(source["categories"], TypeStat);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScanResult {
+	    root?: FileNode;
+	    totalSize: number;
+	    totalFiles: number;
+	    totalDirectories: number;
+	    // Go type: time
+	    scanTime: any;
+	    scanDuration: number;
+	    fileTypes?: TypeBreakdown;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.root = this.convertValues(source["root"], FileNode);
+	        this.totalSize = source["totalSize"];
+	        this.totalFiles = source["totalFiles"];
+	        this.totalDirectories = source["totalDirectories"];
+	        this.scanTime = this.convertValues(source["scanTime"], null);
+	        this.scanDuration = source["scanDuration"];
+	        this.fileTypes = this.convertValues(source["fileTypes"], TypeBreakdown);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+
+}
+
+export namespace oci {
+	
+	export class Layer {
+	    index: number;
+	    digest: string;
+	    size: number;
+	    uncompressedSize: number;
+	    visibleSize: number;
+	    shadowedSize: number;
+	    files: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Layer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.digest = source["digest"];
+	        this.size = source["size"];
+	        this.uncompressedSize = source["uncompressedSize"];
+	        this.visibleSize = source["visibleSize"];
+	        this.shadowedSize = source["shadowedSize"];
+	        this.files = source["files"];
+	    }
+	}
+	export class Image {
+	    name: string;
+	    layers: Layer[];
+	    result?: models.ScanResult;
+	
+	    static createFrom(source: any = {}) {
+	        return new Image(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.layers = this.convertValues(source["layers"], Layer);
+	        this.result = this.convertValues(source["result"], models.ScanResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace report {
+	
+	export class Options {
+	    title: string;
+	    topN: number;
+	    maxDepth: number;
+	    minShare: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Options(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.topN = source["topN"];
+	        this.maxDepth = source["maxDepth"];
+	        this.minShare = source["minShare"];
+	    }
+	}
+
+}
+
+export namespace scanner {
+	
+	export class ScanOptions {
+	    showHiddenFiles: boolean;
+	    followSymlinks: boolean;
+	    excludePatterns: string[];
+	    respectGitignore: boolean;
+	    maxDepth: number;
+	    maxFileSize: number;
+	    expandArchives: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.showHiddenFiles = source["showHiddenFiles"];
+	        this.followSymlinks = source["followSymlinks"];
+	        this.excludePatterns = source["excludePatterns"];
+	        this.respectGitignore = source["respectGitignore"];
+	        this.maxDepth = source["maxDepth"];
+	        this.maxFileSize = source["maxFileSize"];
+	        this.expandArchives = source["expandArchives"];
+	    }
+	}
+
+}
+
+export namespace search {
+	
+	export class Ancestor {
+	    id: string;
+	    name: string;
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Ancestor(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.path = source["path"];
+	    }
+	}
+	export class Hit {
 	    id: string;
 	    name: string;
 	    path: string;
 	    size: number;
 	    type: string;
-	    children?: FileNode[];
 	    // Go type: time
 	    lastModified: any;
-	    isHidden: boolean;
-	    permissions?: string;
+	    isVirtual?: boolean;
+	    ancestors: Ancestor[];
 	
 	    static createFrom(source: any = {}) {
-	        return new FileNode(source);
+	        return new Hit(source);
 	    }
 	
 	    constructor(source: any = {}) {
@@ -23,10 +1099,9 @@ export namespace models {
 	        this.path = source["path"];
 	        this.size = source["size"];
 	        this.type = source["type"];
-	        this.children = this.convertValues(source["children"], FileNode);
 	        this.lastModified = this.convertValues(source["lastModified"], null);
-	        this.isHidden = source["isHidden"];
-	        this.permissions = source["permissions"];
+	        this.isVirtual = source["isVirtual"];
+	        this.ancestors = this.convertValues(source["ancestors"], Ancestor);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -47,27 +1122,220 @@ export namespace models {
 		    return a;
 		}
 	}
-	export class ScanResult {
-	    root?: FileNode;
+	export class Query {
+	    pattern: string;
+	    mode: string;
+	    field: string;
+	    caseSensitive: boolean;
+	    type: string;
+	    extensions: string[];
+	    minSize: number;
+	    maxSize: number;
+	    // Go type: time
+	    modifiedAfter: any;
+	    // Go type: time
+	    modifiedBefore: any;
+	    expression: string;
+	    sortBy: string;
+	    offset: number;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Query(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.mode = source["mode"];
+	        this.field = source["field"];
+	        this.caseSensitive = source["caseSensitive"];
+	        this.type = source["type"];
+	        this.extensions = source["extensions"];
+	        this.minSize = source["minSize"];
+	        this.maxSize = source["maxSize"];
+	        this.modifiedAfter = this.convertValues(source["modifiedAfter"], null);
+	        this.modifiedBefore = this.convertValues(source["modifiedBefore"], null);
+	        this.expression = source["expression"];
+	        this.sortBy = source["sortBy"];
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Results {
+	    hits: Hit[];
+	    total: number;
+	    offset: number;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Results(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hits = this.convertValues(source["hits"], Hit);
+	        this.total = source["total"];
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace services {
+	
+	export class RemovalSkip {
+	    path: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RemovalSkip(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class RemovalResult {
+	    dryRun: boolean;
+	    removed: string[];
+	    skipped: RemovalSkip[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RemovalResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dryRun = source["dryRun"];
+	        this.removed = source["removed"];
+	        this.skipped = this.convertValues(source["skipped"], RemovalSkip);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace snapshot {
+	
+	export class Info {
+	    id: string;
+	    label: string;
+	    path: string;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    scanTime: any;
 	    totalSize: number;
 	    totalFiles: number;
 	    totalDirectories: number;
-	    // Go type: time
-	    scanTime: any;
-	    scanDuration: number;
+	    options?: scanner.ScanOptions;
+	    fileSize: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ScanResult(source);
+	        return new Info(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.root = this.convertValues(source["root"], FileNode);
+	        this.id = source["id"];
+	        this.label = source["label"];
+	        this.path = source["path"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.scanTime = this.convertValues(source["scanTime"], null);
 	        this.totalSize = source["totalSize"];
 	        this.totalFiles = source["totalFiles"];
 	        this.totalDirectories = source["totalDirectories"];
-	        this.scanTime = this.convertValues(source["scanTime"], null);
-	        this.scanDuration = source["scanDuration"];
+	        this.options = this.convertValues(source["options"], scanner.ScanOptions);
+	        this.fileSize = source["fileSize"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Snapshot {
+	    info?: Info;
+	    result?: models.ScanResult;
+	
+	    static createFrom(source: any = {}) {
+	        return new Snapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.info = this.convertValues(source["info"], Info);
+	        this.result = this.convertValues(source["result"], models.ScanResult);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -91,3 +1359,64 @@ export namespace models {
 
 }
 
+export namespace treemap {
+	
+	export class Options {
+	    width: number;
+	    height: number;
+	    maxDepth: number;
+	    minArea: number;
+	    padding: number;
+	    header: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Options(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.maxDepth = source["maxDepth"];
+	        this.minArea = source["minArea"];
+	        this.padding = source["padding"];
+	        this.header = source["header"];
+	    }
+	}
+	export class Tile {
+	    x: number;
+	    y: number;
+	    w: number;
+	    h: number;
+	    id: string;
+	    name: string;
+	    path: string;
+	    size: number;
+	    type: string;
+	    depth: number;
+	    isVirtual?: boolean;
+	    truncated?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Tile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.x = source["x"];
+	        this.y = source["y"];
+	        this.w = source["w"];
+	        this.h = source["h"];
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.type = source["type"];
+	        this.depth = source["depth"];
+	        this.isVirtual = source["isVirtual"];
+	        this.truncated = source["truncated"];
+	    }
+	}
+
+}
+
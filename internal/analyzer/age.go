@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+// AgeBucket holds the files whose age falls in [MinDays, MaxDays). A MaxDays
+// of zero means the bucket is open-ended.
+type AgeBucket struct {
+	Label   string `json:"label"`
+	MinDays int    `json:"minDays"`
+	MaxDays int    `json:"maxDays"`
+	Size    int64  `json:"size"`
+	Count   int64  `json:"count"`
+}
+
+// StaleEntry is a file or subtree with nothing modified or, where recorded,
+// accessed since the cutoff.
+type StaleEntry struct {
+	Path        string    `json:"path"`
+	Type        string    `json:"type"`
+	Size        int64     `json:"size"`
+	LastTouched time.Time `json:"lastTouched"`
+}
+
+type AgeReport struct {
+	Modified []*AgeBucket `json:"modified"`
+	// Accessed is empty when the filesystem records no access times
+	Accessed []*AgeBucket  `json:"accessed"`
+	Stale    []*StaleEntry `json:"stale"`
+	Cutoff   time.Time     `json:"cutoff"`
+}
+
+type AgeOptions struct {
+	Now              time.Time `json:"now"`
+	StaleAfterMonths int       `json:"staleAfterMonths"`
+	MaxStale         int       `json:"maxStale"`
+	MinStaleSize     int64     `json:"minStaleSize"`
+	UseAccessTime    bool      `json:"useAccessTime"`
+}
+
+var ageBucketBounds = []struct {
+	label   string
+	maxDays int
+}{
+	{"< 1 month", 30},
+	{"1-3 months", 90},
+	{"3-6 months", 180},
+	{"6-12 months", 365},
+	{"1-2 years", 730},
+	{"2-5 years", 1825},
+	{"> 5 years", 0},
+}
+
+func DefaultAgeOptions() *AgeOptions {
+	return &AgeOptions{
+		Now:              time.Now(),
+		StaleAfterMonths: 12,
+		MaxStale:         100,
+		MinStaleSize:     1024 * 1024, // 1MB
+		UseAccessTime:    true,
+	}
+}
+
+// AnalyzeAge buckets file bytes by modification and access age and lists the
+// largest subtrees untouched since the stale cutoff. Only the outermost stale
+// subtree is listed, not each of its stale descendants.
+func AnalyzeAge(root *models.FileNode, options *AgeOptions) *AgeReport {
+	if options == nil {
+		options = DefaultAgeOptions()
+	}
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	report := &AgeReport{
+		Modified: newAgeBuckets(),
+		Cutoff:   now.AddDate(0, -options.StaleAfterMonths, 0),
+	}
+	accessed := newAgeBuckets()
+	hasAccessTimes := false
+
+	walkFiles(root, func(node *models.FileNode) {
+		addToBucket(report.Modified, now.Sub(node.LastModified), node.Size)
+		if !node.LastAccessed.IsZero() {
+			hasAccessTimes = true
+			addToBucket(accessed, now.Sub(node.LastAccessed), node.Size)
+		}
+	})
+	if hasAccessTimes {
+		report.Accessed = accessed
+	}
+
+	if root != nil && options.StaleAfterMonths > 0 {
+		collectStale(root, report, options)
+		sort.Slice(report.Stale, func(i, j int) bool {
+			if report.Stale[i].Size != report.Stale[j].Size {
+				return report.Stale[i].Size > report.Stale[j].Size
+			}
+			return report.Stale[i].Path < report.Stale[j].Path
+		})
+		if options.MaxStale > 0 && len(report.Stale) > options.MaxStale {
+			report.Stale = report.Stale[:options.MaxStale]
+		}
+	}
+
+	return report
+}
+
+func newAgeBuckets() []*AgeBucket {
+	buckets := make([]*AgeBucket, len(ageBucketBounds))
+	minDays := 0
+	for i, bound := range ageBucketBounds {
+		buckets[i] = &AgeBucket{Label: bound.label, MinDays: minDays, MaxDays: bound.maxDays}
+		minDays = bound.maxDays
+	}
+	return buckets
+}
+
+func addToBucket(buckets []*AgeBucket, age time.Duration, size int64) {
+	days := int(age.Hours() / 24)
+	for _, bucket := range buckets {
+		if bucket.MaxDays == 0 || days < bucket.MaxDays {
+			bucket.Size += size
+			bucket.Count++
+			return
+		}
+	}
+}
+
+// collectStale fills report.Stale with the outermost subtrees whose most
+// recent touch time is before the cutoff.
+func collectStale(root *models.FileNode, report *AgeReport, options *AgeOptions) {
+	var visit func(node *models.FileNode)
+	visit = func(node *models.FileNode) {
+		latest := models.LastTouched(node, options.UseAccessTime)
+		if latest.Before(report.Cutoff) {
+			// The whole subtree is stale and replaces its descendants
+			if node.Size >= options.MinStaleSize {
+				report.Stale = append(report.Stale, &StaleEntry{
+					Path:        node.Path,
+					Type:        node.Type,
+					Size:        node.Size,
+					LastTouched: latest,
+				})
+			}
+			return
+		}
+		if node.Type == scanner.FileTypeFile {
+			return
+		}
+		for _, child := range node.Children {
+			if !child.IsVirtual {
+				visit(child)
+			}
+		}
+	}
+	visit(root)
+}
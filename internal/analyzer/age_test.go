@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"vizdisk/internal/vfs"
+)
+
+func TestAnalyzeAge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	m := vfs.NewMemFS()
+
+	files := []struct {
+		path     string
+		size     int
+		modified time.Time
+		accessed time.Time
+	}{
+		{"/data/active/new.txt", 100, now.AddDate(0, 0, -3), time.Time{}},
+		{"/data/active/old.txt", 200, now.AddDate(-3, 0, 0), time.Time{}},
+		{"/data/archive/2019/a.bin", 5000, now.AddDate(-6, 0, 0), time.Time{}},
+		{"/data/archive/2019/b.bin", 3000, now.AddDate(-2, -1, 0), time.Time{}},
+		{"/data/archive/notes.txt", 10, now.AddDate(-2, 0, 0), time.Time{}},
+		{"/data/reference/manual.pdf", 4000, now.AddDate(-4, 0, 0), now.AddDate(0, -1, 0)},
+	}
+	for _, f := range files {
+		_ = m.WriteFile(f.path, make([]byte, f.size))
+		_ = m.Chtimes(f.path, f.modified)
+		if !f.accessed.IsZero() {
+			_ = m.SetAccessTime(f.path, f.accessed)
+		}
+	}
+	for _, dir := range []string{"/data", "/data/active", "/data/archive", "/data/archive/2019", "/data/reference"} {
+		_ = m.Chtimes(dir, now.AddDate(-5, 0, 0))
+	}
+
+	result := scanMemFS(t, m, "/data")
+	report := AnalyzeAge(result.Root, &AgeOptions{Now: now, StaleAfterMonths: 12, MinStaleSize: 50, UseAccessTime: true})
+
+	if report.Modified[0].Size != 100 || report.Modified[0].Count != 1 {
+		t.Errorf("Modified[0] = %+v, want the new file", report.Modified[0])
+	}
+	if last := report.Modified[len(report.Modified)-1]; last.Size != 5000 {
+		t.Errorf("oldest bucket = %+v, want 5000 bytes", last)
+	}
+	if len(report.Accessed) == 0 || report.Accessed[1].Size != 4000 {
+		t.Errorf("Accessed = %+v, want access time bucket for manual.pdf", report.Accessed)
+	}
+
+	if len(report.Stale) != 2 {
+		t.Fatalf("Stale = %d entries, want 2", len(report.Stale))
+	}
+	if report.Stale[0].Path != "/data/archive" || report.Stale[0].Size != 8010 {
+		t.Errorf("Stale[0] = %+v, want /data/archive as one subtree", report.Stale[0])
+	}
+	if report.Stale[1].Path != "/data/active/old.txt" {
+		t.Errorf("Stale[1] = %+v, want /data/active/old.txt", report.Stale[1])
+	}
+
+	withoutAtime := AnalyzeAge(result.Root, &AgeOptions{Now: now, StaleAfterMonths: 12, MinStaleSize: 50})
+	if len(withoutAtime.Stale) != 3 {
+		t.Errorf("Stale without access times = %d entries, want 3", len(withoutAtime.Stale))
+	}
+}
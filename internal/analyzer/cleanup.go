@@ -0,0 +1,254 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/query"
+	"vizdisk/internal/scanner"
+)
+
+const (
+	SafetySafe    = "safe"
+	SafetyCaution = "caution"
+	SafetyRisky   = "risky"
+
+	CleanupActionDelete = "delete"
+	CleanupActionTrash  = "trash"
+	CleanupActionReport = "report"
+)
+
+// CleanupRule recognizes a regenerable directory. A directory matches when
+// its name matches one of Names or its path ends with one of PathSuffixes,
+// and, if Markers are given, a sibling entry matches one of them. Where
+// further restricts matches with a query language expression such as
+// "size > 1GB". Matches used within the last MinAgeDays are not reported.
+type CleanupRule struct {
+	ID           string   `json:"id" yaml:"id"`
+	Description  string   `json:"description" yaml:"description"`
+	Names        []string `json:"names,omitempty" yaml:"names"`
+	PathSuffixes []string `json:"pathSuffixes,omitempty" yaml:"pathSuffixes"`
+	Markers      []string `json:"markers,omitempty" yaml:"markers"`
+	Safety       string   `json:"safety" yaml:"safety"`
+	MinAgeDays   int      `json:"minAgeDays,omitempty" yaml:"minAgeDays"`
+	Action       string   `json:"action,omitempty" yaml:"action"`
+	Where        string   `json:"where,omitempty" yaml:"where"`
+	// Descend keeps looking for more specific candidates inside a match,
+	// for broad containers such as ~/.cache
+	Descend bool `json:"descend,omitempty" yaml:"descend"`
+}
+
+type CleanupCandidate struct {
+	Path        string    `json:"path"`
+	RuleID      string    `json:"ruleId"`
+	Description string    `json:"description"`
+	Size        int64     `json:"size"`
+	LastUsed    time.Time `json:"lastUsed"`
+	Safety      string    `json:"safety"`
+	Action      string    `json:"action"`
+}
+
+type CleanupReport struct {
+	Candidates []*CleanupCandidate `json:"candidates"`
+	TotalSize  int64               `json:"totalSize"`
+	// RuleErrors lists problems in the user rules file; the rules that
+	// failed validation are left out of the analysis
+	RuleErrors []*RuleError `json:"ruleErrors,omitempty"`
+}
+
+type CleanupOptions struct {
+	Now time.Time `json:"now"`
+	// RecentDays downgrades safe candidates used more recently to caution
+	RecentDays int `json:"recentDays"`
+}
+
+func DefaultCleanupOptions() *CleanupOptions {
+	return &CleanupOptions{
+		Now:        time.Now(),
+		RecentDays: 7,
+	}
+}
+
+func DefaultCleanupRules() []*CleanupRule {
+	return []*CleanupRule{
+		{ID: "node-modules", Description: "npm/yarn dependencies", Names: []string{"node_modules"}, Markers: []string{"package.json"}, Safety: SafetySafe},
+		{ID: "rust-target", Description: "Rust build output", Names: []string{"target"}, Markers: []string{"Cargo.toml"}, Safety: SafetySafe},
+		{ID: "maven-target", Description: "Maven build output", Names: []string{"target"}, Markers: []string{"pom.xml"}, Safety: SafetySafe},
+		{ID: "gradle-project", Description: "Gradle project cache", Names: []string{".gradle"}, Markers: []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"}, Safety: SafetySafe},
+		{ID: "gradle-home", Description: "Gradle dependency cache", PathSuffixes: []string{".gradle/caches"}, Safety: SafetyCaution},
+		{ID: "pycache", Description: "Python bytecode cache", Names: []string{"__pycache__", ".pytest_cache", ".mypy_cache"}, Safety: SafetySafe},
+		{ID: "python-venv", Description: "Python virtual environment", Names: []string{".venv", "venv"}, Markers: []string{"pyproject.toml", "requirements.txt", "setup.py", "Pipfile"}, Safety: SafetyCaution},
+		{ID: "go-build-cache", Description: "Go build cache", PathSuffixes: []string{".cache/go-build", "Library/Caches/go-build", "AppData/Local/go-build"}, Safety: SafetySafe},
+		{ID: "xcode-derived-data", Description: "Xcode DerivedData", PathSuffixes: []string{"Library/Developer/Xcode/DerivedData"}, Safety: SafetySafe},
+		{ID: "user-cache", Description: "Per-user application caches", PathSuffixes: []string{".cache", "Library/Caches"}, Safety: SafetyCaution, Descend: true},
+	}
+}
+
+// CleanupAnalyzer walks a scanned tree and reports directories matching its
+// rules. Rules are tried in order and the first match wins.
+type CleanupAnalyzer struct {
+	rules   []*CleanupRule
+	where   map[*CleanupRule]query.Predicate
+	options *CleanupOptions
+}
+
+func NewCleanupAnalyzer(rules []*CleanupRule, options *CleanupOptions) *CleanupAnalyzer {
+	if rules == nil {
+		rules = DefaultCleanupRules()
+	}
+	if options == nil {
+		options = DefaultCleanupOptions()
+	}
+
+	// Rules with an invalid Where never match; LoadCleanupRules reports them
+	where := make(map[*CleanupRule]query.Predicate)
+	for _, rule := range rules {
+		if rule.Where == "" {
+			continue
+		}
+		match, err := query.Compile(rule.Where, &query.Options{Now: options.Now})
+		if err != nil {
+			match = func(*models.FileNode) bool { return false }
+		}
+		where[rule] = match
+	}
+
+	return &CleanupAnalyzer{
+		rules:   rules,
+		where:   where,
+		options: options,
+	}
+}
+
+func (c *CleanupAnalyzer) Analyze(root *models.FileNode) *CleanupReport {
+	report := &CleanupReport{Candidates: []*CleanupCandidate{}}
+	if root == nil {
+		return report
+	}
+
+	c.visit(root, nil, report)
+
+	sort.Slice(report.Candidates, func(i, j int) bool {
+		if report.Candidates[i].Size != report.Candidates[j].Size {
+			return report.Candidates[i].Size > report.Candidates[j].Size
+		}
+		return report.Candidates[i].Path < report.Candidates[j].Path
+	})
+	for _, candidate := range report.Candidates {
+		report.TotalSize += candidate.Size
+	}
+	return report
+}
+
+// visit returns the bytes below node already claimed by candidates, so that
+// descending rules do not count them twice.
+func (c *CleanupAnalyzer) visit(node, parent *models.FileNode, report *CleanupReport) int64 {
+	if node.Type == scanner.FileTypeFile || node.IsVirtual {
+		return 0
+	}
+
+	rule := c.match(node, parent)
+	if rule != nil && !rule.Descend {
+		if candidate := c.candidate(node, rule, node.Size); candidate != nil {
+			report.Candidates = append(report.Candidates, candidate)
+		}
+		return node.Size
+	}
+
+	var claimed int64
+	for _, child := range node.Children {
+		claimed += c.visit(child, node, report)
+	}
+
+	if rule != nil && node.Size > claimed {
+		if candidate := c.candidate(node, rule, node.Size-claimed); candidate != nil {
+			report.Candidates = append(report.Candidates, candidate)
+		}
+		return node.Size
+	}
+	return claimed
+}
+
+func (c *CleanupAnalyzer) match(node, parent *models.FileNode) *CleanupRule {
+	for _, rule := range c.rules {
+		if !ruleMatches(rule, node, parent) {
+			continue
+		}
+		if where, ok := c.where[rule]; ok && !where(node) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+func ruleMatches(rule *CleanupRule, node, parent *models.FileNode) bool {
+	matched := false
+	for _, pattern := range rule.Names {
+		if ok, _ := filepath.Match(pattern, node.Name); ok {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		slashPath := filepath.ToSlash(node.Path)
+		for _, suffix := range rule.PathSuffixes {
+			if slashPath == suffix || strings.HasSuffix(slashPath, "/"+strings.Trim(suffix, "/")) {
+				matched = true
+				break
+			}
+		}
+	}
+	if !matched {
+		return false
+	}
+
+	if len(rule.Markers) == 0 {
+		return true
+	}
+	if parent == nil {
+		return false
+	}
+	for _, sibling := range parent.Children {
+		for _, marker := range rule.Markers {
+			if ok, _ := filepath.Match(marker, sibling.Name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// candidate returns nil when the match was used too recently for its rule.
+func (c *CleanupAnalyzer) candidate(node *models.FileNode, rule *CleanupRule, size int64) *CleanupCandidate {
+	lastUsed := models.LastTouched(node, true)
+	if rule.MinAgeDays > 0 && lastUsed.After(c.options.Now.AddDate(0, 0, -rule.MinAgeDays)) {
+		return nil
+	}
+
+	safety := rule.Safety
+	if safety == "" {
+		safety = SafetyCaution
+	}
+	action := rule.Action
+	if action == "" {
+		action = CleanupActionDelete
+	}
+	recent := c.options.Now.AddDate(0, 0, -c.options.RecentDays)
+	if safety == SafetySafe && c.options.RecentDays > 0 && lastUsed.After(recent) {
+		safety = SafetyCaution
+	}
+
+	return &CleanupCandidate{
+		Path:        node.Path,
+		RuleID:      rule.ID,
+		Description: rule.Description,
+		Size:        size,
+		LastUsed:    lastUsed,
+		Safety:      safety,
+		Action:      action,
+	}
+}
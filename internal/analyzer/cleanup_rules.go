@@ -0,0 +1,180 @@
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"vizdisk/internal/query"
+	"vizdisk/internal/vfs"
+)
+
+// CleanupRulesVersion is the rules file format understood by this build.
+const CleanupRulesVersion = 1
+
+// CleanupRulesFile is the user rules file. JSON files are accepted as well,
+// since JSON is valid YAML.
+//
+//	version: 1
+//	rules:
+//	  - id: ci-artifacts
+//	    description: CI build artifacts
+//	    names: [".ci-out", "artifacts"]
+//	    markers: [".gitlab-ci.yml"]
+//	    minAgeDays: 30
+//	    where: size > 100MB
+//	    safety: safe
+//	    action: trash
+type CleanupRulesFile struct {
+	Version int            `json:"version" yaml:"version"`
+	Rules   []*CleanupRule `json:"rules" yaml:"rules"`
+}
+
+// RuleError describes one problem in a rules file. Rule is the index of the
+// offending rule, or -1 for errors affecting the whole file.
+type RuleError struct {
+	Rule    int    `json:"rule"`
+	ID      string `json:"id,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *RuleError) Error() string {
+	if e.Rule < 0 {
+		return e.Message
+	}
+	name := fmt.Sprintf("rule %d", e.Rule+1)
+	if e.ID != "" {
+		name += fmt.Sprintf(" (%s)", e.ID)
+	}
+	if e.Field != "" {
+		name += " " + e.Field
+	}
+	return name + ": " + e.Message
+}
+
+// RuleErrors collects every problem found in a rules file.
+type RuleErrors []*RuleError
+
+func (e RuleErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// LoadCleanupRules reads and validates a rules file. Valid rules are returned
+// alongside a RuleErrors error describing the ones that were dropped.
+func LoadCleanupRules(fsys vfs.FS, path string) ([]*CleanupRule, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cleanup rules: %w", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cleanup rules: %w", err)
+	}
+	return ParseCleanupRules(data)
+}
+
+// ParseCleanupRules decodes a YAML or JSON rules file. Unknown fields are
+// rejected so that typos do not silently disable part of a rule.
+func ParseCleanupRules(data []byte) ([]*CleanupRule, error) {
+	var file CleanupRulesFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, RuleErrors{{Rule: -1, Message: err.Error()}}
+	}
+
+	if file.Version != CleanupRulesVersion {
+		return nil, RuleErrors{{Rule: -1, Field: "version", Message: fmt.Sprintf("unsupported version %d, want %d", file.Version, CleanupRulesVersion)}}
+	}
+
+	var valid []*CleanupRule
+	var errs RuleErrors
+	seen := make(map[string]bool)
+	for i, rule := range file.Rules {
+		ruleErrs := validateCleanupRule(i, rule)
+		if rule != nil && rule.ID != "" {
+			if seen[rule.ID] {
+				ruleErrs = append(ruleErrs, &RuleError{Rule: i, ID: rule.ID, Field: "id", Message: "duplicate rule id"})
+			}
+			seen[rule.ID] = true
+		}
+		if len(ruleErrs) > 0 {
+			errs = append(errs, ruleErrs...)
+			continue
+		}
+		valid = append(valid, rule)
+	}
+
+	if len(errs) > 0 {
+		return valid, errs
+	}
+	return valid, nil
+}
+
+func validateCleanupRule(index int, rule *CleanupRule) RuleErrors {
+	if rule == nil {
+		return RuleErrors{{Rule: index, Message: "empty rule"}}
+	}
+
+	var errs RuleErrors
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, &RuleError{Rule: index, ID: rule.ID, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if rule.ID == "" {
+		fail("id", "is required")
+	}
+	if len(rule.Names) == 0 && len(rule.PathSuffixes) == 0 {
+		fail("names", "a rule needs names or pathSuffixes to match")
+	}
+	checkPatterns := func(field string, patterns []string) {
+		for _, pattern := range patterns {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				fail(field, "invalid pattern %q", pattern)
+			} else if strings.ContainsAny(pattern, `/\`) {
+				fail(field, "pattern %q must match a single name", pattern)
+			}
+		}
+	}
+	checkPatterns("names", rule.Names)
+	checkPatterns("markers", rule.Markers)
+	for _, suffix := range rule.PathSuffixes {
+		if strings.Trim(suffix, "/") == "" {
+			fail("pathSuffixes", "empty path suffix")
+		}
+	}
+	if rule.Where != "" {
+		if _, err := query.Compile(rule.Where, nil); err != nil {
+			fail("where", "%v", err)
+		}
+	}
+	if rule.MinAgeDays < 0 {
+		fail("minAgeDays", "must not be negative")
+	}
+	switch rule.Safety {
+	case "", SafetySafe, SafetyCaution, SafetyRisky:
+	default:
+		fail("safety", "unknown safety %q, want safe, caution or risky", rule.Safety)
+	}
+	switch rule.Action {
+	case "", CleanupActionDelete, CleanupActionTrash, CleanupActionReport:
+	default:
+		fail("action", "unknown action %q, want delete, trash or report", rule.Action)
+	}
+	return errs
+}
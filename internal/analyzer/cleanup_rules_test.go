@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"vizdisk/internal/vfs"
+)
+
+func TestParseCleanupRules(t *testing.T) {
+	yamlRules := `
+version: 1
+rules:
+  - id: ci-artifacts
+    description: CI build artifacts
+    names: [artifacts]
+    markers: [.gitlab-ci.yml]
+    minAgeDays: 30
+    safety: safe
+    action: trash
+  - id: broken
+    names: ["[bad"]
+    safety: reckless
+  - description: no id
+    pathSuffixes: [datasets/cache]
+  - id: bad-where
+    names: [cache]
+    where: size >> 1GB
+`
+	rules, err := ParseCleanupRules([]byte(yamlRules))
+	var ruleErrs RuleErrors
+	if !errors.As(err, &ruleErrs) {
+		t.Fatalf("ParseCleanupRules() error = %v, want RuleErrors", err)
+	}
+	if len(rules) != 1 || rules[0].ID != "ci-artifacts" || rules[0].MinAgeDays != 30 {
+		t.Fatalf("rules = %+v, want only ci-artifacts", rules)
+	}
+	if len(ruleErrs) != 4 {
+		t.Fatalf("errors = %v, want 4", ruleErrs)
+	}
+	if ruleErrs[0].Rule != 1 || ruleErrs[0].Field != "names" || ruleErrs[2].Rule != 2 || ruleErrs[2].Field != "id" || ruleErrs[3].Field != "where" {
+		t.Errorf("errors = %v", ruleErrs)
+	}
+
+	jsonRules := `{"version": 1, "rules": [{"id": "ds", "pathSuffixes": ["datasets/cache"]}]}`
+	rules, err = ParseCleanupRules([]byte(jsonRules))
+	if err != nil || len(rules) != 1 || rules[0].PathSuffixes[0] != "datasets/cache" {
+		t.Errorf("JSON rules = %+v, %v", rules, err)
+	}
+
+	for name, data := range map[string]string{
+		"unknown field": "version: 1\nrules:\n  - id: x\n    name: [x]\n",
+		"version":       "version: 2\nrules: []\n",
+		"syntax":        "version: [1\n",
+	} {
+		if _, err := ParseCleanupRules([]byte(data)); !errors.As(err, &ruleErrs) || ruleErrs[0].Rule != -1 {
+			t.Errorf("%s: error = %v, want a file-level RuleError", name, err)
+		}
+	}
+}
+
+func TestCleanupAnalyzer_UserRules(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/rules.yaml", []byte(`
+version: 1
+rules:
+  - id: ci-artifacts
+    names: [artifacts]
+    markers: [.gitlab-ci.yml]
+    minAgeDays: 30
+    where: size > 100
+    safety: safe
+    action: trash
+`))
+	_ = m.WriteFile("/repos/old/.gitlab-ci.yml", nil)
+	_ = m.WriteFile("/repos/old/artifacts/build.tar", make([]byte, 400))
+	_ = m.Chtimes("/repos/old/artifacts/build.tar", now.AddDate(0, -2, 0))
+	_ = m.WriteFile("/repos/new/.gitlab-ci.yml", nil)
+	_ = m.WriteFile("/repos/new/artifacts/build.tar", make([]byte, 400))
+	_ = m.Chtimes("/repos/new/artifacts/build.tar", now.AddDate(0, 0, -2))
+	_ = m.WriteFile("/repos/plain/artifacts/keep.tar", make([]byte, 400))
+	_ = m.WriteFile("/repos/tiny/.gitlab-ci.yml", nil)
+	_ = m.WriteFile("/repos/tiny/artifacts/log.txt", make([]byte, 50))
+	_ = m.Chtimes("/repos/tiny/artifacts/log.txt", now.AddDate(0, -2, 0))
+
+	rules, err := LoadCleanupRules(m, "/rules.yaml")
+	if err != nil {
+		t.Fatalf("LoadCleanupRules() error = %v", err)
+	}
+
+	result := scanMemFS(t, m, "/repos")
+	report := NewCleanupAnalyzer(rules, &CleanupOptions{Now: now}).Analyze(result.Root)
+	if len(report.Candidates) != 1 {
+		t.Fatalf("Candidates = %d, want 1", len(report.Candidates))
+	}
+	c := report.Candidates[0]
+	if c.Path != "/repos/old/artifacts" || c.Action != CleanupActionTrash || c.Safety != SafetySafe {
+		t.Errorf("candidate = %+v", c)
+	}
+}
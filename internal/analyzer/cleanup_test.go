@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"vizdisk/internal/vfs"
+)
+
+func TestCleanupAnalyzer(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	m := vfs.NewMemFS()
+
+	files := map[string]int{
+		"/home/me/web/package.json":                            10,
+		"/home/me/web/node_modules/react/index.js":             500,
+		"/home/me/web/node_modules/a/node_modules/b/index.js":  300,
+		"/home/me/rust/Cargo.toml":                             10,
+		"/home/me/rust/target/debug/app":                       2000,
+		"/home/me/notes/target/goals.md":                       50,
+		"/home/me/py/app/__pycache__/mod.pyc":                  40,
+		"/home/me/py/requirements.txt":                         10,
+		"/home/me/py/.venv/lib/site.py":                        700,
+		"/home/me/.cache/go-build/00/abc":                      1500,
+		"/home/me/.cache/thumbnails/x.png":                     250,
+		"/home/me/Library/Developer/Xcode/DerivedData/App/obj": 900,
+	}
+	old := now.AddDate(0, -3, 0)
+	for path, size := range files {
+		_ = m.WriteFile(path, make([]byte, size))
+		_ = m.Chtimes(path, old)
+	}
+	_ = m.Chtimes("/home/me/rust/target/debug/app", now.AddDate(0, 0, -1))
+
+	result := scanMemFS(t, m, "/home/me")
+	report := NewCleanupAnalyzer(nil, &CleanupOptions{Now: now, RecentDays: 7}).Analyze(result.Root)
+
+	want := map[string]struct {
+		rule   string
+		size   int64
+		safety string
+	}{
+		"/home/me/rust/target":                         {"rust-target", 2000, SafetyCaution},
+		"/home/me/.cache/go-build":                     {"go-build-cache", 1500, SafetySafe},
+		"/home/me/Library/Developer/Xcode/DerivedData": {"xcode-derived-data", 900, SafetySafe},
+		"/home/me/web/node_modules":                    {"node-modules", 800, SafetySafe},
+		"/home/me/py/.venv":                            {"python-venv", 700, SafetyCaution},
+		"/home/me/.cache":                              {"user-cache", 250, SafetyCaution},
+		"/home/me/py/app/__pycache__":                  {"pycache", 40, SafetySafe},
+	}
+	if len(report.Candidates) != len(want) {
+		for _, c := range report.Candidates {
+			t.Logf("candidate %s (%s) %d", c.Path, c.RuleID, c.Size)
+		}
+		t.Fatalf("Candidates = %d, want %d", len(report.Candidates), len(want))
+	}
+	for _, c := range report.Candidates {
+		w, ok := want[c.Path]
+		if !ok {
+			t.Errorf("unexpected candidate %s", c.Path)
+			continue
+		}
+		if c.RuleID != w.rule || c.Size != w.size || c.Safety != w.safety {
+			t.Errorf("%s = %s/%d/%s, want %s/%d/%s", c.Path, c.RuleID, c.Size, c.Safety, w.rule, w.size, w.safety)
+		}
+	}
+	if report.Candidates[0].Path != "/home/me/rust/target" {
+		t.Errorf("first candidate = %s, want the largest", report.Candidates[0].Path)
+	}
+	if report.TotalSize != 6190 {
+		t.Errorf("TotalSize = %d, want 6190", report.TotalSize)
+	}
+	if !report.Candidates[0].LastUsed.Equal(now.AddDate(0, 0, -1)) {
+		t.Errorf("LastUsed = %v, want the recent build", report.Candidates[0].LastUsed)
+	}
+}
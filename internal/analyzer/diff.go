@@ -0,0 +1,208 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+const (
+	DiffAdded     = "added"
+	DiffRemoved   = "removed"
+	DiffGrown     = "grown"
+	DiffShrunk    = "shrunk"
+	DiffChanged   = "changed" // files came and went but the total is the same
+	DiffUnchanged = "unchanged"
+)
+
+type DiffOptions struct {
+	// IncludeUnchanged keeps files and directories without any change in
+	// the delta tree, which is needed to draw the full treemap
+	IncludeUnchanged bool `json:"includeUnchanged"`
+}
+
+// DeltaNode is a node of either scan with the bytes that changed beneath it.
+// Added and Removed count files that exist in only one scan, Grown and
+// Shrunk the size changes of files present in both, so that
+// Delta = Added - Removed + Grown - Shrunk.
+type DeltaNode struct {
+	ID       string       `json:"id"`
+	Name     string       `json:"name"`
+	Path     string       `json:"path"`
+	Type     string       `json:"type"`
+	Status   string       `json:"status"`
+	OldSize  int64        `json:"oldSize"`
+	NewSize  int64        `json:"newSize"`
+	Delta    int64        `json:"delta"`
+	Added    int64        `json:"added"`
+	Removed  int64        `json:"removed"`
+	Grown    int64        `json:"grown"`
+	Shrunk   int64        `json:"shrunk"`
+	Children []*DeltaNode `json:"children,omitempty"`
+}
+
+type DiffReport struct {
+	Root        *DeltaNode `json:"root"`
+	OldScanTime time.Time  `json:"oldScanTime"`
+	NewScanTime time.Time  `json:"newScanTime"`
+}
+
+// Diff compares two scans of the same tree. Nodes are matched by their path
+// relative to the root, so scans of a moved or remounted tree compare too.
+// Children are ordered by the size of their change, largest first.
+func Diff(before, after *models.ScanResult, options *DiffOptions) *DiffReport {
+	if options == nil {
+		options = &DiffOptions{}
+	}
+	report := &DiffReport{}
+	var oldRoot, newRoot *models.FileNode
+	if before != nil {
+		oldRoot = before.Root
+		report.OldScanTime = before.ScanTime
+	}
+	if after != nil {
+		newRoot = after.Root
+		report.NewScanTime = after.ScanTime
+	}
+
+	report.Root = diffNodes(oldRoot, newRoot, options)
+	if report.Root == nil {
+		report.Root = &DeltaNode{Status: DiffUnchanged}
+	}
+	if newRoot != nil {
+		// The root is always kept and named after the newer scan
+		report.Root.ID, report.Root.Name, report.Root.Path = newRoot.ID, newRoot.Name, newRoot.Path
+	}
+	return report
+}
+
+// diffNodes returns the delta of a pair of matched nodes, either of which
+// may be missing, or nil if it is unchanged and unchanged nodes are dropped.
+func diffNodes(before, after *models.FileNode, options *DiffOptions) *DeltaNode {
+	if before != nil && before.IsVirtual {
+		before = nil
+	}
+	if after != nil && after.IsVirtual {
+		after = nil
+	}
+	if before == nil && after == nil {
+		return nil
+	}
+
+	ref := after
+	if ref == nil {
+		ref = before
+	}
+	delta := &DeltaNode{ID: ref.ID, Name: ref.Name, Path: ref.Path, Type: ref.Type}
+	if before != nil {
+		delta.OldSize = before.Size
+	}
+	if after != nil {
+		delta.NewSize = after.Size
+	}
+	delta.Delta = delta.NewSize - delta.OldSize
+
+	if !isDir(ref) {
+		switch {
+		case before == nil:
+			delta.Added = after.Size
+		case after == nil:
+			delta.Removed = before.Size
+		case delta.Delta > 0:
+			delta.Grown = delta.Delta
+		case delta.Delta < 0:
+			delta.Shrunk = -delta.Delta
+		}
+	} else {
+		for _, pair := range matchChildren(before, after) {
+			// A path that changed between file and directory is a removal
+			// plus an addition
+			if pair[0] != nil && pair[1] != nil && isDir(pair[0]) != isDir(pair[1]) {
+				delta.addChild(diffNodes(pair[0], nil, options))
+				delta.addChild(diffNodes(nil, pair[1], options))
+				continue
+			}
+			delta.addChild(diffNodes(pair[0], pair[1], options))
+		}
+		sort.SliceStable(delta.Children, func(i, j int) bool {
+			a, b := abs(delta.Children[i].Delta), abs(delta.Children[j].Delta)
+			if a != b {
+				return a > b
+			}
+			return delta.Children[i].Name < delta.Children[j].Name
+		})
+	}
+
+	delta.Status = diffStatus(before, after, delta)
+	if delta.Status == DiffUnchanged && !options.IncludeUnchanged {
+		return nil
+	}
+	return delta
+}
+
+func (d *DeltaNode) addChild(child *DeltaNode) {
+	if child == nil {
+		return
+	}
+	d.Added += child.Added
+	d.Removed += child.Removed
+	d.Grown += child.Grown
+	d.Shrunk += child.Shrunk
+	d.Children = append(d.Children, child)
+}
+
+func diffStatus(before, after *models.FileNode, delta *DeltaNode) string {
+	switch {
+	case before == nil:
+		return DiffAdded
+	case after == nil:
+		return DiffRemoved
+	case delta.Delta > 0:
+		return DiffGrown
+	case delta.Delta < 0:
+		return DiffShrunk
+	case delta.Added+delta.Removed+delta.Grown+delta.Shrunk > 0:
+		return DiffChanged
+	}
+	return DiffUnchanged
+}
+
+// matchChildren pairs the children of two directories by name, keeping the
+// newer scan's order and appending removed entries.
+func matchChildren(before, after *models.FileNode) [][2]*models.FileNode {
+	oldByName := make(map[string]*models.FileNode)
+	if before != nil {
+		for _, child := range before.Children {
+			oldByName[child.Name] = child
+		}
+	}
+
+	var pairs [][2]*models.FileNode
+	if after != nil {
+		for _, child := range after.Children {
+			pairs = append(pairs, [2]*models.FileNode{oldByName[child.Name], child})
+			delete(oldByName, child.Name)
+		}
+	}
+	if before != nil {
+		for _, child := range before.Children {
+			if _, ok := oldByName[child.Name]; ok {
+				pairs = append(pairs, [2]*models.FileNode{child, nil})
+			}
+		}
+	}
+	return pairs
+}
+
+func isDir(node *models.FileNode) bool {
+	return node.Type == scanner.FileTypeDirectory
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"testing"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/vfs"
+)
+
+func findDelta(node *DeltaNode, path string) *DeltaNode {
+	if node == nil {
+		return nil
+	}
+	if node.Path == path {
+		return node
+	}
+	for _, child := range node.Children {
+		if found := findDelta(child, path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func TestDiff(t *testing.T) {
+	before := vfs.NewMemFS()
+	_ = before.WriteFile("/data/logs/app.log", make([]byte, 1000))
+	_ = before.WriteFile("/data/logs/old.log", make([]byte, 300))
+	_ = before.WriteFile("/data/src/main.go", make([]byte, 50))
+	_ = before.WriteFile("/data/cache/blob", make([]byte, 400))
+	_ = before.WriteFile("/data/build", make([]byte, 10))
+	_ = before.WriteFile("/data/docs/a.md", make([]byte, 100))
+
+	after := vfs.NewMemFS()
+	_ = after.WriteFile("/data/logs/app.log", make([]byte, 5000))
+	_ = after.WriteFile("/data/src/main.go", make([]byte, 50))
+	_ = after.WriteFile("/data/src/util.go", make([]byte, 20))
+	_ = after.WriteFile("/data/build/out.bin", make([]byte, 700))
+	_ = after.WriteFile("/data/docs/a.md", make([]byte, 40))
+	_ = after.WriteFile("/data/docs/b.md", make([]byte, 60))
+
+	report := Diff(scanMemFS(t, before, "/data"), scanMemFS(t, after, "/data"), nil)
+	root := report.Root
+
+	if root.Path != "/data" || root.Status != DiffGrown {
+		t.Errorf("root = %s %s, want /data grown", root.Path, root.Status)
+	}
+	if root.Added != 20+700+60 || root.Removed != 300+400+10 || root.Grown != 4000 || root.Shrunk != 60 {
+		t.Errorf("root bytes = +%d -%d grown %d shrunk %d", root.Added, root.Removed, root.Grown, root.Shrunk)
+	}
+	if root.Delta != root.Added-root.Removed+root.Grown-root.Shrunk {
+		t.Errorf("root delta %d does not balance", root.Delta)
+	}
+
+	tests := []struct {
+		path   string
+		status string
+		delta  int64
+	}{
+		{"/data/logs", DiffGrown, 3700},
+		{"/data/logs/old.log", DiffRemoved, -300},
+		{"/data/cache", DiffRemoved, -400},
+		{"/data/src/util.go", DiffAdded, 20},
+		{"/data/docs", DiffChanged, 0},
+		{"/data/docs/a.md", DiffShrunk, -60},
+	}
+	for _, tt := range tests {
+		node := findDelta(root, tt.path)
+		if node == nil {
+			t.Errorf("%s missing from the delta tree", tt.path)
+			continue
+		}
+		if node.Status != tt.status || node.Delta != tt.delta {
+			t.Errorf("%s = %s %d, want %s %d", tt.path, node.Status, node.Delta, tt.status, tt.delta)
+		}
+	}
+
+	// A file replaced by a directory shows up as both
+	var build []*DeltaNode
+	for _, child := range root.Children {
+		if child.Name == "build" {
+			build = append(build, child)
+		}
+	}
+	if len(build) != 2 {
+		t.Errorf("build entries = %d, want a removed file and an added directory", len(build))
+	}
+
+	if findDelta(root, "/data/src/main.go") != nil {
+		t.Error("unchanged file should be dropped")
+	}
+	if root.Children[0].Name != "logs" {
+		t.Errorf("largest change first, got %s", root.Children[0].Name)
+	}
+}
+
+func TestDiff_IncludeUnchanged(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/a.txt", make([]byte, 10))
+	_ = m.WriteFile("/data/sub/b.txt", make([]byte, 20))
+	result := scanMemFS(t, m, "/data")
+
+	report := Diff(result, result, nil)
+	if report.Root.Status != DiffUnchanged || len(report.Root.Children) != 0 {
+		t.Errorf("identical scans = %s with %d children", report.Root.Status, len(report.Root.Children))
+	}
+
+	report = Diff(result, result, &DiffOptions{IncludeUnchanged: true})
+	if node := findDelta(report.Root, "/data/sub/b.txt"); node == nil || node.NewSize != 20 || node.Status != DiffUnchanged {
+		t.Errorf("unchanged file = %+v", node)
+	}
+}
+
+func TestDiff_SkipsVirtualEntries(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/a.zip", make([]byte, 10))
+	before := scanMemFS(t, m, "/data")
+	after := scanMemFS(t, m, "/data")
+	archive := after.Root.Children[0]
+	archive.Children = []*models.FileNode{{Name: "inner.txt", Path: "/data/a.zip/inner.txt", Size: 100, Type: "file", IsVirtual: true}}
+
+	if report := Diff(before, after, nil); len(report.Root.Children) != 0 {
+		t.Errorf("virtual entries should not count as changes, got %+v", report.Root.Children[0])
+	}
+}
@@ -0,0 +1,295 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+type DirectoryDuplicateOptions struct {
+	MinSize    int64   `json:"minSize"`
+	Similarity float64 `json:"similarity"`
+}
+
+// DirectoryMatch is a pair of directories with identical or largely shared
+// content. Similarity is the fraction of the larger directory's bytes found
+// in the other one.
+type DirectoryMatch struct {
+	PathA       string  `json:"pathA"`
+	PathB       string  `json:"pathB"`
+	SizeA       int64   `json:"sizeA"`
+	SizeB       int64   `json:"sizeB"`
+	SharedBytes int64   `json:"sharedBytes"`
+	Similarity  float64 `json:"similarity"`
+	Identical   bool    `json:"identical"`
+}
+
+type DirectoryDuplicateReport struct {
+	Matches          []*DirectoryMatch `json:"matches"`
+	ReclaimableBytes int64             `json:"reclaimableBytes"`
+}
+
+func DefaultDirectoryDuplicateOptions() *DirectoryDuplicateOptions {
+	return &DirectoryDuplicateOptions{
+		MinSize:    1024 * 1024, // 1MB
+		Similarity: 0.9,
+	}
+}
+
+// maxNearCandidates bounds the near-match search: content shared by more
+// directories than this, such as a license file in every vendored package, is
+// too common to suggest a pair and is not used to look up candidates.
+const maxNearCandidates = 32
+
+type dirInfo struct {
+	node *models.FileNode
+	// parent is the nearest ancestor that is also a candidate
+	parent    *dirInfo
+	signature string
+	contents  map[string]int
+	// keys are the contents not already held by a candidate subdirectory.
+	// Pairs are looked up by them, and ancestors are reached by climbing
+	// from those pairs rather than paired for every file they contain.
+	keys []string
+	// wrapper is set when a single subdirectory holds all of the
+	// directory's bytes; such directories would only repeat its matches
+	wrapper bool
+}
+
+// FindDuplicateDirectories compares directory subtrees using the file hashes
+// of a duplicate file report. Each directory gets a Merkle-style signature
+// over its entry names and content hashes; equal signatures mean identical
+// subtrees. Near-identical pairs are found by comparing the multisets of
+// duplicated file contents below each directory. Matches nested inside an
+// already matched pair are left out.
+func FindDuplicateDirectories(result *models.ScanResult, files *DuplicateReport, options *DirectoryDuplicateOptions) *DirectoryDuplicateReport {
+	if options == nil {
+		options = DefaultDirectoryDuplicateOptions()
+	}
+	report := &DirectoryDuplicateReport{}
+	if result == nil || result.Root == nil {
+		return report
+	}
+
+	hashes := make(map[string]string)
+	hashSizes := make(map[string]int64)
+	if files != nil {
+		for _, group := range files.Groups {
+			hashSizes[group.Hash] = group.Size
+			for _, path := range group.Paths {
+				hashes[path] = group.Hash
+			}
+		}
+	}
+	dirs := collectDirectories(result.Root, hashes, options.MinSize)
+
+	matches := make(map[[2]string]*DirectoryMatch)
+	addMatch := func(a, b *dirInfo, shared int64, similarity float64, identical bool) {
+		if a.node.Path > b.node.Path {
+			a, b = b, a
+		}
+		matches[[2]string{a.node.Path, b.node.Path}] = &DirectoryMatch{
+			PathA:       a.node.Path,
+			PathB:       b.node.Path,
+			SizeA:       a.node.Size,
+			SizeB:       b.node.Size,
+			SharedBytes: shared,
+			Similarity:  similarity,
+			Identical:   identical,
+		}
+	}
+
+	bySignature := make(map[string][]*dirInfo)
+	for _, dir := range dirs {
+		bySignature[dir.signature] = append(bySignature[dir.signature], dir)
+	}
+	for _, group := range bySignature {
+		for i := 1; i < len(group); i++ {
+			addMatch(group[0], group[i], group[i].node.Size, 1, true)
+		}
+	}
+
+	if options.Similarity > 0 && options.Similarity < 1 {
+		nearMatches(dirs, hashSizes, options.Similarity, func(a, b *dirInfo, shared int64, similarity float64) {
+			addMatch(a, b, shared, similarity, false)
+		})
+	}
+
+	for key, match := range matches {
+		parentA, parentB := filepath.Dir(key[0]), filepath.Dir(key[1])
+		if matches[[2]string{parentA, parentB}] != nil || matches[[2]string{parentB, parentA}] != nil {
+			continue
+		}
+		report.Matches = append(report.Matches, match)
+		if match.Identical {
+			report.ReclaimableBytes += match.SizeB
+		}
+	}
+
+	sort.Slice(report.Matches, func(i, j int) bool {
+		a, b := report.Matches[i], report.Matches[j]
+		if a.Identical != b.Identical {
+			return a.Identical
+		}
+		if a.SharedBytes != b.SharedBytes {
+			return a.SharedBytes > b.SharedBytes
+		}
+		return a.PathA < b.PathA
+	})
+	return report
+}
+
+// collectDirectories computes the signature and contents of every directory
+// below root and returns those of at least minSize bytes.
+func collectDirectories(root *models.FileNode, hashes map[string]string, minSize int64) []*dirInfo {
+	var dirs []*dirInfo
+	var visit func(node *models.FileNode) (string, map[string]int, *dirInfo)
+	visit = func(node *models.FileNode) (string, map[string]int, *dirInfo) {
+		if node.Type == scanner.FileTypeFile {
+			if hash, ok := hashes[node.Path]; ok {
+				return hash, map[string]int{hash: 1}, nil
+			}
+			// Empty files are left out of duplicate reports but all have
+			// the same content, so markers such as __init__.py and
+			// .gitkeep do not keep directories from matching
+			if node.Size == 0 {
+				return "empty", nil, nil
+			}
+			// A file with no duplicate anywhere makes its directory unique
+			return "unique:" + node.Path, nil, nil
+		}
+
+		children := append([]*models.FileNode(nil), node.Children...)
+		sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+
+		h := sha256.New()
+		contents := make(map[string]int)
+		covered := make(map[string]bool)
+		var subdirs []*dirInfo
+		wrapper := false
+		for _, child := range children {
+			if child.IsVirtual {
+				continue
+			}
+			if child.Type != scanner.FileTypeFile && child.Size == node.Size {
+				wrapper = true
+			}
+			childSig, childContents, childInfo := visit(child)
+			h.Write([]byte(child.Type + "\x00" + child.Name + "\x00" + childSig + "\x00"))
+			for hash, count := range childContents {
+				contents[hash] += count
+			}
+			if childInfo != nil {
+				subdirs = append(subdirs, childInfo)
+				for hash := range childContents {
+					covered[hash] = true
+				}
+			}
+		}
+
+		sig := hex.EncodeToString(h.Sum(nil))
+		if node.Size < minSize || node.Size == 0 {
+			return sig, contents, nil
+		}
+		dir := &dirInfo{node: node, signature: sig, contents: contents, wrapper: wrapper}
+		for hash := range contents {
+			if !covered[hash] {
+				dir.keys = append(dir.keys, hash)
+			}
+		}
+		for _, sub := range subdirs {
+			sub.parent = dir
+		}
+		dirs = append(dirs, dir)
+		return sig, contents, dir
+	}
+	visit(root)
+	return dirs
+}
+
+// nearMatches calls add for every pair of directories that are at least
+// similarity alike and returns how many pairs it compared. Candidates are the
+// directories sharing a key, so each one is compared with a handful of others
+// and not with every ancestor of every copy of its files; the ancestors of
+// each pair are then compared for as long as their sizes stay close enough.
+func nearMatches(dirs []*dirInfo, hashSizes map[string]int64, similarity float64, add func(a, b *dirInfo, shared int64, similarity float64)) int {
+	index := make(map[string][]*dirInfo)
+	for _, dir := range dirs {
+		for _, hash := range dir.keys {
+			index[hash] = append(index[hash], dir)
+		}
+	}
+
+	compared := make(map[[2]*dirInfo]bool)
+	var queue [][2]*dirInfo
+	push := func(a, b *dirInfo) {
+		if a == nil || b == nil || a == b || nested(a.node.Path, b.node.Path) {
+			return
+		}
+		if a.node.Path > b.node.Path {
+			a, b = b, a
+		}
+		key := [2]*dirInfo{a, b}
+		if !compared[key] {
+			compared[key] = true
+			queue = append(queue, key)
+		}
+	}
+	for _, group := range index {
+		if len(group) > maxNearCandidates {
+			continue
+		}
+		for i, a := range group {
+			for _, b := range group[i+1:] {
+				push(a, b)
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		a, b := queue[0][0], queue[0][1]
+		queue = queue[1:]
+
+		// Climbing only adds bytes, so a pair too far apart in size can
+		// only come closer through the ancestors of its smaller side
+		larger := max(a.node.Size, b.node.Size)
+		threshold := similarity * float64(larger)
+		if float64(a.node.Size) < threshold {
+			push(a.parent, b)
+			continue
+		}
+		if float64(b.node.Size) < threshold {
+			push(a, b.parent)
+			continue
+		}
+		push(a.parent, b.parent)
+		push(a.parent, b)
+		push(a, b.parent)
+
+		if a.wrapper || b.wrapper || a.signature == b.signature {
+			continue
+		}
+		var shared int64
+		for h, count := range a.contents {
+			shared += int64(min(count, b.contents[h])) * hashSizes[h]
+		}
+		if ratio := float64(shared) / float64(larger); ratio >= similarity {
+			add(a, b, shared, ratio)
+		}
+	}
+	return len(compared)
+}
+
+func nested(a, b string) bool {
+	return isUnder(b, a) || isUnder(a, b)
+}
+
+// isUnder reports whether path lies strictly below dir.
+func isUnder(path, dir string) bool {
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
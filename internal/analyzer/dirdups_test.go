@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"vizdisk/internal/vfs"
+)
+
+func TestFindDuplicateDirectories(t *testing.T) {
+	m := vfs.NewMemFS()
+	photo := bytes.Repeat([]byte("p"), 5000)
+	video := bytes.Repeat([]byte("v"), 90000)
+	notes := bytes.Repeat([]byte("n"), 3000)
+	code := bytes.Repeat([]byte("c"), 40000)
+	assets := bytes.Repeat([]byte("a"), 20000)
+
+	for _, root := range []string{"/data/photos", "/data/backup/photos"} {
+		_ = m.WriteFile(root+"/2023/img1.jpg", photo)
+		_ = m.WriteFile(root+"/2023/clip.mp4", video)
+	}
+	_ = m.WriteFile("/data/project/main.go", code)
+	_ = m.WriteFile("/data/project/logo.png", assets)
+	_ = m.WriteFile("/data/project-copy/main.go", code)
+	_ = m.WriteFile("/data/project-copy/logo.png", assets)
+	_ = m.WriteFile("/data/project-copy/notes.txt", notes)
+
+	result := scanMemFS(t, m, "/data")
+	files, err := NewDuplicateFinder(m, nil).Find(context.Background(), result, nil)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	report := FindDuplicateDirectories(result, files, &DirectoryDuplicateOptions{MinSize: 1000, Similarity: 0.9})
+
+	var identical, near int
+	for _, match := range report.Matches {
+		if match.Identical {
+			identical++
+			if match.PathA != "/data/backup/photos" || match.PathB != "/data/photos" {
+				t.Errorf("identical match = %+v, want the photos folders", match)
+			}
+		} else {
+			near++
+			if match.PathA != "/data/project" || match.PathB != "/data/project-copy" {
+				t.Errorf("near match = %+v, want the project folders", match)
+			}
+			if match.Similarity < 0.9 || match.Similarity >= 1 {
+				t.Errorf("near match similarity = %v", match.Similarity)
+			}
+		}
+	}
+	if identical != 1 || near != 1 {
+		t.Errorf("matches = %d identical, %d near, want 1 each: %+v", identical, near, report.Matches)
+	}
+	if report.ReclaimableBytes != int64(len(photo)+len(video)) {
+		t.Errorf("ReclaimableBytes = %d, want %d", report.ReclaimableBytes, len(photo)+len(video))
+	}
+}
+
+func TestFindDuplicateDirectories_NamesMatter(t *testing.T) {
+	m := vfs.NewMemFS()
+	content := bytes.Repeat([]byte("x"), 2000)
+	_ = m.WriteFile("/data/a/one", content)
+	_ = m.WriteFile("/data/b/two", content)
+
+	result := scanMemFS(t, m, "/data")
+	files, _ := NewDuplicateFinder(m, nil).Find(context.Background(), result, nil)
+
+	report := FindDuplicateDirectories(result, files, &DirectoryDuplicateOptions{MinSize: 1, Similarity: 0.9})
+	if len(report.Matches) != 1 || report.Matches[0].Identical {
+		t.Errorf("Matches = %+v, want one near-identical pair", report.Matches)
+	}
+}
+
+func TestFindDuplicateDirectories_EmptyFiles(t *testing.T) {
+	m := vfs.NewMemFS()
+	content := bytes.Repeat([]byte("x"), 2000)
+	for _, root := range []string{"/data/pkg", "/data/pkg-copy"} {
+		_ = m.WriteFile(root+"/__init__.py", nil)
+		_ = m.WriteFile(root+"/module.py", content)
+	}
+
+	result := scanMemFS(t, m, "/data")
+	files, _ := NewDuplicateFinder(m, nil).Find(context.Background(), result, nil)
+
+	report := FindDuplicateDirectories(result, files, &DirectoryDuplicateOptions{MinSize: 1, Similarity: 0.9})
+	if len(report.Matches) != 1 || !report.Matches[0].Identical {
+		t.Errorf("Matches = %+v, want one identical pair", report.Matches)
+	}
+}
+
+func TestFindDuplicateDirectories_WideDeepTree(t *testing.T) {
+	m := vfs.NewMemFS()
+	license := bytes.Repeat([]byte("l"), 1000)
+	library := bytes.Repeat([]byte("b"), 50000)
+	for i := range 200 {
+		root := fmt.Sprintf("/data/vendor/pkg%03d/src/a/b/c", i)
+		_ = m.WriteFile(root+"/LICENSE", license)
+		_ = m.WriteFile(root+"/lib.js", library)
+		_ = m.WriteFile(root+"/version", []byte(fmt.Sprint(i)))
+	}
+	big := bytes.Repeat([]byte("x"), 80000)
+	_ = m.WriteFile("/data/app/deep/er/main.bin", big)
+	_ = m.WriteFile("/data/app-old/deep/er/main.bin", big)
+	_ = m.WriteFile("/data/app-old/deep/er/changelog", []byte("older"))
+	// Files only found in one place each keep app from wrapping deep, so the
+	// app folders can only be reached by climbing from their subfolders
+	_ = m.WriteFile("/data/app/README", []byte("new readme"))
+	_ = m.WriteFile("/data/app-old/README", []byte("old readme"))
+
+	result := scanMemFS(t, m, "/data")
+	files, err := NewDuplicateFinder(m, nil).Find(context.Background(), result, nil)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	hashes := make(map[string]string)
+	hashSizes := make(map[string]int64)
+	for _, group := range files.Groups {
+		hashSizes[group.Hash] = group.Size
+		for _, path := range group.Paths {
+			hashes[path] = group.Hash
+		}
+	}
+	dirs := collectDirectories(result.Root, hashes, 1)
+	var near []string
+	compared := nearMatches(dirs, hashSizes, 0.9, func(a, b *dirInfo, _ int64, _ float64) {
+		near = append(near, a.node.Path+" "+b.node.Path)
+	})
+
+	// Every package shares its license and library with the other 199, and
+	// so does each of its five ancestors. Pairing all of them would compare
+	// over 700,000 pairs.
+	if compared > 50 {
+		t.Errorf("compared %d pairs of %d directories", compared, len(dirs))
+	}
+	if !slices.Contains(near, "/data/app /data/app-old") {
+		t.Errorf("near matches = %q, want the app folders", near)
+	}
+}
@@ -0,0 +1,432 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+const (
+	DuplicateStageSize    = "size"
+	DuplicateStagePartial = "partial"
+	DuplicateStageFull    = "full"
+	DuplicateStageDone    = "done"
+)
+
+// progressInterval limits how often hashing progress is reported
+const progressInterval = 100 * time.Millisecond
+
+type DuplicateOptions struct {
+	MinSize     int64 `json:"minSize"`
+	Workers     int   `json:"workers"`
+	PartialSize int64 `json:"partialSize"`
+}
+
+type DuplicateGroup struct {
+	Hash             string   `json:"hash"`
+	Size             int64    `json:"size"`
+	Paths            []string `json:"paths"`
+	ReclaimableBytes int64    `json:"reclaimableBytes"`
+}
+
+type DuplicateReport struct {
+	Groups           []*DuplicateGroup `json:"groups"`
+	TotalReclaimable int64             `json:"totalReclaimable"`
+	FilesCompared    int64             `json:"filesCompared"`
+	BytesHashed      int64             `json:"bytesHashed"`
+}
+
+type DuplicateProgress struct {
+	Stage       string `json:"stage"`
+	Processed   int64  `json:"processed"`
+	Total       int64  `json:"total"`
+	BytesHashed int64  `json:"bytesHashed"`
+}
+
+// DuplicateFinder narrows candidates in stages: files are grouped by size,
+// then by a hash of their first and last bytes, and only the survivors are
+// hashed in full.
+type DuplicateFinder struct {
+	fs      vfs.FS
+	options *DuplicateOptions
+}
+
+func DefaultDuplicateOptions() *DuplicateOptions {
+	return &DuplicateOptions{
+		MinSize:     1,
+		Workers:     runtime.NumCPU(),
+		PartialSize: 4096,
+	}
+}
+
+func NewDuplicateFinder(fsys vfs.FS, options *DuplicateOptions) *DuplicateFinder {
+	if options == nil {
+		options = DefaultDuplicateOptions()
+	}
+
+	return &DuplicateFinder{
+		fs:      fsys,
+		options: options,
+	}
+}
+
+func (f *DuplicateFinder) Find(ctx context.Context, result *models.ScanResult, progressCallback func(*DuplicateProgress)) (*DuplicateReport, error) {
+	if result == nil || result.Root == nil {
+		return nil, fmt.Errorf("scan result is empty")
+	}
+
+	tracker := &progressTracker{callback: progressCallback, interval: progressInterval}
+	report := &DuplicateReport{}
+
+	tracker.start(DuplicateStageSize, 0)
+	candidates := f.groupBySize(result.Root)
+
+	var total int64
+	for _, group := range candidates {
+		total += int64(len(group))
+	}
+	report.FilesCompared = total
+
+	tracker.start(DuplicateStagePartial, total)
+	partial, err := f.refine(ctx, candidates, tracker, f.partialHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files no larger than head and tail together were already hashed in
+	// full during the partial stage
+	var needFull [][]*models.FileNode
+	var final []hashedGroup
+	total = 0
+	for _, group := range partial {
+		if group.nodes[0].Size > 2*f.options.PartialSize {
+			needFull = append(needFull, group.nodes)
+			total += int64(len(group.nodes))
+		} else {
+			final = append(final, group)
+		}
+	}
+
+	tracker.start(DuplicateStageFull, total)
+	full, err := f.refine(ctx, needFull, tracker, f.fullHash)
+	if err != nil {
+		return nil, err
+	}
+	final = append(final, full...)
+
+	for _, group := range final {
+		paths := make([]string, 0, len(group.nodes))
+		for _, node := range group.nodes {
+			paths = append(paths, node.Path)
+		}
+		sort.Strings(paths)
+
+		size := group.nodes[0].Size
+		reclaimable := size * int64(len(group.nodes)-1)
+		report.Groups = append(report.Groups, &DuplicateGroup{
+			Hash:             group.hash,
+			Size:             size,
+			Paths:            paths,
+			ReclaimableBytes: reclaimable,
+		})
+		report.TotalReclaimable += reclaimable
+	}
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].ReclaimableBytes != report.Groups[j].ReclaimableBytes {
+			return report.Groups[i].ReclaimableBytes > report.Groups[j].ReclaimableBytes
+		}
+		return report.Groups[i].Paths[0] < report.Groups[j].Paths[0]
+	})
+
+	report.BytesHashed = tracker.bytesHashed()
+	tracker.start(DuplicateStageDone, 0)
+	return report, nil
+}
+
+func (f *DuplicateFinder) groupBySize(root *models.FileNode) [][]*models.FileNode {
+	bySize := make(map[int64][]*models.FileNode)
+	walkFiles(root, func(node *models.FileNode) {
+		if node.Size >= f.options.MinSize && node.Size > 0 {
+			bySize[node.Size] = append(bySize[node.Size], node)
+		}
+	})
+
+	var groups [][]*models.FileNode
+	for _, nodes := range bySize {
+		if len(nodes) > 1 {
+			nodes = f.distinctFiles(nodes)
+		}
+		if len(nodes) > 1 {
+			groups = append(groups, nodes)
+		}
+	}
+	return groups
+}
+
+type fileID struct {
+	device, inode uint64
+}
+
+// distinctFiles keeps one path per file: hard links and symlinks share the
+// device and inode of their target, and removing them frees nothing. The
+// path that sorts first is kept. Files that cannot be identified are kept.
+func (f *DuplicateFinder) distinctFiles(nodes []*models.FileNode) []*models.FileNode {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Path < nodes[j].Path })
+	seen := make(map[fileID]bool, len(nodes))
+	distinct := nodes[:0]
+	for _, node := range nodes {
+		if info, err := f.fs.Stat(node.Path); err == nil {
+			md := vfs.MetadataOf(info)
+			id := fileID{md.Device, md.Inode}
+			if md.Inode != 0 && seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		distinct = append(distinct, node)
+	}
+	return distinct
+}
+
+type hashFunc func(ctx context.Context, node *models.FileNode) (string, int64, error)
+
+type hashedNode struct {
+	group int
+	node  *models.FileNode
+	hash  string
+}
+
+type hashedGroup struct {
+	hash  string
+	nodes []*models.FileNode
+}
+
+// refine hashes every candidate concurrently and splits each group by hash,
+// dropping files that turn out to be unique. Unreadable files are skipped.
+func (f *DuplicateFinder) refine(ctx context.Context, groups [][]*models.FileNode, tracker *progressTracker, hash hashFunc) ([]hashedGroup, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan hashedNode)
+	results := make(chan hashedNode)
+
+	workers := f.options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				sum, n, err := hash(ctx, job.node)
+				tracker.advance(n)
+				if err != nil {
+					continue
+				}
+				job.hash = sum
+				select {
+				case results <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i, group := range groups {
+			for _, node := range group {
+				select {
+				case jobs <- hashedNode{group: i, node: node}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	byHash := make(map[int]map[string][]*models.FileNode)
+	for job := range results {
+		if byHash[job.group] == nil {
+			byHash[job.group] = make(map[string][]*models.FileNode)
+		}
+		byHash[job.group][job.hash] = append(byHash[job.group][job.hash], job.node)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("duplicate search canceled: %w", err)
+	}
+
+	var refined []hashedGroup
+	for _, hashes := range byHash {
+		for sum, nodes := range hashes {
+			if len(nodes) > 1 {
+				refined = append(refined, hashedGroup{hash: sum, nodes: nodes})
+			}
+		}
+	}
+	return refined, nil
+}
+
+// partialHash hashes the first and last PartialSize bytes, or the whole file
+// when it is smaller than both together.
+func (f *DuplicateFinder) partialHash(ctx context.Context, node *models.FileNode) (string, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return "", 0, err
+	}
+
+	file, err := f.fs.Open(node.Path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	chunk := f.options.PartialSize
+	if node.Size <= 2*chunk {
+		n, err := io.Copy(h, file)
+		return hex.EncodeToString(h.Sum(nil)), n, err
+	}
+
+	buf := make([]byte, chunk)
+	if _, err := file.ReadAt(buf, 0); err != nil {
+		return "", 0, err
+	}
+	h.Write(buf)
+	if _, err := file.ReadAt(buf, node.Size-chunk); err != nil {
+		return "", chunk, err
+	}
+	h.Write(buf)
+	return hex.EncodeToString(h.Sum(nil)), 2 * chunk, nil
+}
+
+func (f *DuplicateFinder) fullHash(ctx context.Context, node *models.FileNode) (string, int64, error) {
+	file, err := f.fs.Open(node.Path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, &contextReader{ctx: ctx, r: file})
+	if err != nil {
+		return "", n, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// contextReader aborts long reads once the context is canceled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// progressTracker reports hashing progress at most once per interval and
+// always reports the start and the last file of each stage. The callback runs
+// outside the lock so that workers are not held up while it is delivered.
+type progressTracker struct {
+	mu       sync.Mutex
+	callback func(*DuplicateProgress)
+	interval time.Duration
+	progress DuplicateProgress
+	hashed   int64
+	lastEmit time.Time
+	seq      uint64
+
+	emitMu sync.Mutex
+	sent   uint64
+}
+
+func (t *progressTracker) start(stage string, total int64) {
+	t.mu.Lock()
+	t.progress = DuplicateProgress{Stage: stage, Total: total, BytesHashed: t.hashed}
+	progress, seq := t.next()
+	t.mu.Unlock()
+
+	t.emit(progress, seq)
+}
+
+func (t *progressTracker) advance(bytes int64) {
+	t.mu.Lock()
+	t.hashed += bytes
+	t.progress.Processed++
+	t.progress.BytesHashed = t.hashed
+	if t.progress.Processed < t.progress.Total && time.Since(t.lastEmit) < t.interval {
+		t.mu.Unlock()
+		return
+	}
+	progress, seq := t.next()
+	t.mu.Unlock()
+
+	t.emit(progress, seq)
+}
+
+func (t *progressTracker) bytesHashed() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.hashed
+}
+
+// next numbers a copy of the current progress; t.mu must be held.
+func (t *progressTracker) next() (DuplicateProgress, uint64) {
+	t.lastEmit = time.Now()
+	t.seq++
+	return t.progress, t.seq
+}
+
+// emit delivers progress unless a newer update already went out, which can
+// happen when another worker passes it between unlocking and emitting.
+func (t *progressTracker) emit(progress DuplicateProgress, seq uint64) {
+	if t.callback == nil {
+		return
+	}
+	t.emitMu.Lock()
+	defer t.emitMu.Unlock()
+	if seq <= t.sent {
+		return
+	}
+	t.sent = seq
+	t.callback(&progress)
+}
+
+// walkFiles calls fn for every real file below root. Virtual entries such as
+// archive members are skipped since they cannot be read or deleted.
+func walkFiles(root *models.FileNode, fn func(*models.FileNode)) {
+	if root == nil || root.IsVirtual {
+		return
+	}
+	if root.Type == scanner.FileTypeFile {
+		fn(root)
+		return
+	}
+	for _, child := range root.Children {
+		walkFiles(child, fn)
+	}
+}
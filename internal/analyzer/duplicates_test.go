@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"sync"
+	"testing"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+func scanMemFS(t *testing.T, m *vfs.MemFS, root string) *models.ScanResult {
+	t.Helper()
+	options := scanner.DefaultScanOptions()
+	options.ShowHiddenFiles = true
+	options.ExcludePatterns = nil
+	result, err := scanner.NewScannerWithFS(m, options).ScanPath(root, nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	return result
+}
+
+func TestDuplicateFinder_Find(t *testing.T) {
+	m := vfs.NewMemFS()
+	big := bytes.Repeat([]byte("abcdefgh"), 2048)
+	bigVariant := append(bytes.Repeat([]byte("abcdefgh"), 1024), bytes.Repeat([]byte("ABCDEFGH"), 1024)...)
+	copy(bigVariant[len(bigVariant)-8:], big[len(big)-8:])
+	copy(bigVariant[:8], big[:8])
+
+	_ = m.WriteFile("/data/a/big1", big)
+	_ = m.WriteFile("/data/b/big2", big)
+	_ = m.WriteFile("/data/c/big3", big)
+	_ = m.WriteFile("/data/c/variant", bigVariant)
+	_ = m.WriteFile("/data/small1", []byte("same"))
+	_ = m.WriteFile("/data/small2", []byte("same"))
+	_ = m.WriteFile("/data/other", []byte("diff"))
+	_ = m.WriteFile("/data/empty1", nil)
+	_ = m.WriteFile("/data/empty2", nil)
+
+	result := scanMemFS(t, m, "/data")
+
+	options := DefaultDuplicateOptions()
+	options.PartialSize = 64
+	var stages []string
+	report, err := NewDuplicateFinder(m, options).Find(context.Background(), result, func(p *DuplicateProgress) {
+		if len(stages) == 0 || stages[len(stages)-1] != p.Stage {
+			stages = append(stages, p.Stage)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	if len(report.Groups) != 2 {
+		t.Fatalf("Groups = %d, want 2: %+v", len(report.Groups), report.Groups)
+	}
+	first := report.Groups[0]
+	if len(first.Paths) != 3 || first.Paths[0] != "/data/a/big1" || first.ReclaimableBytes != int64(2*len(big)) {
+		t.Errorf("first group = %+v, want three big files", first)
+	}
+	if second := report.Groups[1]; len(second.Paths) != 2 || second.ReclaimableBytes != 4 {
+		t.Errorf("second group = %+v, want the small pair", second)
+	}
+	if report.TotalReclaimable != int64(2*len(big))+4 {
+		t.Errorf("TotalReclaimable = %d", report.TotalReclaimable)
+	}
+
+	want := []string{DuplicateStageSize, DuplicateStagePartial, DuplicateStageFull, DuplicateStageDone}
+	if len(stages) != len(want) {
+		t.Fatalf("stages = %v, want %v", stages, want)
+	}
+	for i := range want {
+		if stages[i] != want[i] {
+			t.Errorf("stages = %v, want %v", stages, want)
+			break
+		}
+	}
+}
+
+func TestDuplicateFinder_Links(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/a/report.pdf", []byte("content"))
+	_ = m.Link("/data/a/report.pdf", "/data/b/hardlink.pdf")
+	_ = m.Symlink("/data/a/report.pdf", "/data/c/symlink.pdf")
+	_ = m.WriteFile("/data/d/copy.pdf", []byte("content"))
+	_ = m.WriteFile("/data/e/alone.txt", []byte("unique!"))
+	_ = m.Link("/data/e/alone.txt", "/data/e/alone-link.txt")
+	result := scanMemFS(t, m, "/data")
+
+	report, err := NewDuplicateFinder(m, nil).Find(context.Background(), result, nil)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(report.Groups) != 1 {
+		t.Fatalf("Groups = %+v, want only the real copy", report.Groups)
+	}
+	group := report.Groups[0]
+	if len(group.Paths) != 2 || group.Paths[0] != "/data/a/report.pdf" || group.Paths[1] != "/data/d/copy.pdf" {
+		t.Errorf("Paths = %v, want the file and its copy without links", group.Paths)
+	}
+	if report.TotalReclaimable != int64(len("content")) {
+		t.Errorf("TotalReclaimable = %d, want one copy", report.TotalReclaimable)
+	}
+}
+
+func TestDuplicateFinder_UnreadableFiles(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/a", []byte("same"))
+	_ = m.WriteFile("/data/b", []byte("same"))
+	result := scanMemFS(t, m, "/data")
+	m.FailOpen("/data/b", fs.ErrPermission)
+
+	report, err := NewDuplicateFinder(m, nil).Find(context.Background(), result, nil)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(report.Groups) != 0 {
+		t.Errorf("Groups = %+v, want none", report.Groups)
+	}
+}
+
+func TestDuplicateFinder_Canceled(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/a", []byte("same"))
+	_ = m.WriteFile("/data/b", []byte("same"))
+	result := scanMemFS(t, m, "/data")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewDuplicateFinder(m, nil).Find(ctx, result, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Find() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestProgressTracker_Throttled(t *testing.T) {
+	var updates []DuplicateProgress
+	tracker := &progressTracker{
+		callback: func(p *DuplicateProgress) { updates = append(updates, *p) },
+		interval: time.Hour,
+	}
+
+	tracker.start(DuplicateStagePartial, 1000)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 125 {
+				tracker.advance(10)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(updates) != 2 {
+		t.Fatalf("updates = %+v, want the start and the last file only", updates)
+	}
+	if last := updates[1]; last.Processed != 1000 || last.BytesHashed != 10000 {
+		t.Errorf("last update = %+v", last)
+	}
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"sort"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+// EmptyDirectory is a directory with no files anywhere below it. Nested counts
+// the empty directories inside it, which are not listed separately.
+type EmptyDirectory struct {
+	Path   string `json:"path"`
+	Nested int    `json:"nested"`
+}
+
+type EmptyReport struct {
+	Directories []*EmptyDirectory `json:"directories"`
+	Files       []string          `json:"files"`
+}
+
+// FindEmpty lists the outermost recursively empty directories and all
+// zero-byte files below root. The root itself is never reported. Entries the
+// scan skipped, such as excluded or hidden files, are unknown here, so
+// callers must check the disk again before removing anything.
+func FindEmpty(root *models.FileNode) *EmptyReport {
+	report := &EmptyReport{
+		Directories: []*EmptyDirectory{},
+		Files:       []string{},
+	}
+	if root == nil {
+		return report
+	}
+
+	walkFiles(root, func(node *models.FileNode) {
+		if node.Size == 0 && !node.IsBrokenLink {
+			report.Files = append(report.Files, node.Path)
+		}
+	})
+
+	// visit returns whether node is empty and how many directories it holds
+	var visit func(node *models.FileNode) (bool, int)
+	visit = func(node *models.FileNode) (bool, int) {
+		if node.Type == scanner.FileTypeFile {
+			return false, 0
+		}
+
+		empty := true
+		nested := 0
+		var emptyChildren []*EmptyDirectory
+		for _, child := range node.Children {
+			if child.IsVirtual {
+				continue
+			}
+			childEmpty, childNested := visit(child)
+			if childEmpty {
+				nested += childNested + 1
+				emptyChildren = append(emptyChildren, &EmptyDirectory{Path: child.Path, Nested: childNested})
+			} else {
+				empty = false
+			}
+		}
+
+		if !empty || node == root {
+			report.Directories = append(report.Directories, emptyChildren...)
+		}
+		return empty, nested
+	}
+	visit(root)
+
+	sort.Slice(report.Directories, func(i, j int) bool { return report.Directories[i].Path < report.Directories[j].Path })
+	sort.Strings(report.Files)
+	return report
+}
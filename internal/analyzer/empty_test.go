@@ -0,0 +1,33 @@
+package analyzer
+
+import (
+	"testing"
+
+	"vizdisk/internal/vfs"
+)
+
+func TestFindEmpty(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.MkdirAll("/data/empty/a/b")
+	_ = m.MkdirAll("/data/empty/c")
+	_ = m.MkdirAll("/data/full/hollow")
+	_ = m.WriteFile("/data/full/file.txt", []byte("x"))
+	_ = m.WriteFile("/data/full/zero.txt", nil)
+	_ = m.WriteFile("/data/zeros/only.txt", nil)
+
+	result := scanMemFS(t, m, "/data")
+	report := FindEmpty(result.Root)
+
+	if len(report.Directories) != 2 {
+		t.Fatalf("Directories = %+v, want 2", report.Directories)
+	}
+	if d := report.Directories[0]; d.Path != "/data/empty" || d.Nested != 3 {
+		t.Errorf("Directories[0] = %+v, want /data/empty with 3 nested", d)
+	}
+	if d := report.Directories[1]; d.Path != "/data/full/hollow" || d.Nested != 0 {
+		t.Errorf("Directories[1] = %+v, want /data/full/hollow", d)
+	}
+	if len(report.Files) != 2 || report.Files[0] != "/data/full/zero.txt" || report.Files[1] != "/data/zeros/only.txt" {
+		t.Errorf("Files = %v", report.Files)
+	}
+}
@@ -0,0 +1,194 @@
+package analyzer
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/vfs"
+)
+
+const (
+	CategoryVideo      = "video"
+	CategoryImages     = "images"
+	CategoryAudio      = "audio"
+	CategoryArchives   = "archives"
+	CategoryCode       = "code"
+	CategoryDocuments  = "documents"
+	CategoryLogs       = "logs"
+	CategoryBinaries   = "binaries"
+	CategoryDiskImages = "disk images"
+	CategoryOther      = "other"
+
+	noExtension = "(none)"
+	sniffSize   = 512
+)
+
+var extensionCategories = map[string]string{}
+
+func init() {
+	for category, extensions := range map[string][]string{
+		CategoryVideo:      {"mp4", "mov", "mkv", "avi", "wmv", "flv", "webm", "m4v", "mpg", "mpeg", "3gp"},
+		CategoryImages:     {"jpg", "jpeg", "png", "gif", "bmp", "tif", "tiff", "webp", "heic", "heif", "raw", "cr2", "nef", "arw", "dng", "svg", "ico", "psd"},
+		CategoryAudio:      {"mp3", "wav", "flac", "aac", "ogg", "m4a", "wma", "aiff", "opus"},
+		CategoryArchives:   {"zip", "tar", "gz", "tgz", "bz2", "xz", "zst", "7z", "rar", "jar", "war", "whl", "deb", "rpm", "apk"},
+		CategoryCode:       {"go", "js", "jsx", "ts", "tsx", "py", "rb", "java", "kt", "c", "h", "cc", "cpp", "hpp", "cs", "rs", "swift", "m", "php", "sh", "css", "scss", "html", "vue", "json", "yaml", "yml", "toml", "xml", "sql", "md"},
+		CategoryDocuments:  {"pdf", "doc", "docx", "xls", "xlsx", "ppt", "pptx", "odt", "ods", "odp", "txt", "rtf", "csv", "epub", "pages", "numbers", "key"},
+		CategoryLogs:       {"log", "out", "err", "trace"},
+		CategoryBinaries:   {"exe", "dll", "so", "dylib", "a", "lib", "o", "obj", "bin", "class", "pyc", "wasm", "node"},
+		CategoryDiskImages: {"iso", "img", "dmg", "vmdk", "vdi", "vhd", "vhdx", "qcow2", "qcow", "sparseimage", "sparsebundle"},
+	} {
+		for _, ext := range extensions {
+			extensionCategories[ext] = category
+		}
+	}
+}
+
+type FileTypeOptions struct {
+	// SniffContent reads the first bytes of files whose extension is not
+	// recognized to classify them by content
+	SniffContent bool `json:"sniffContent"`
+}
+
+// FileTypeAnalyzer aggregates file sizes and counts by extension and by
+// broad category.
+type FileTypeAnalyzer struct {
+	fs      vfs.FS
+	options *FileTypeOptions
+}
+
+func NewFileTypeAnalyzer(fsys vfs.FS, options *FileTypeOptions) *FileTypeAnalyzer {
+	if options == nil {
+		options = &FileTypeOptions{}
+	}
+
+	return &FileTypeAnalyzer{
+		fs:      fsys,
+		options: options,
+	}
+}
+
+func (a *FileTypeAnalyzer) Analyze(root *models.FileNode) *models.TypeBreakdown {
+	extensions := make(map[string]*models.TypeStat)
+	categories := make(map[string]*models.TypeStat)
+
+	add := func(stats map[string]*models.TypeStat, key string, size int64) {
+		stat, ok := stats[key]
+		if !ok {
+			stat = &models.TypeStat{Key: key}
+			stats[key] = stat
+		}
+		stat.Size += size
+		stat.Count++
+	}
+
+	walkFiles(root, func(node *models.FileNode) {
+		ext := models.Extension(node.Name)
+		category := CategoryForExtension(ext)
+		if category == CategoryOther && a.options.SniffContent && a.fs != nil {
+			category = a.sniff(node.Path)
+		}
+
+		if ext == "" {
+			ext = noExtension
+		}
+		add(extensions, ext, node.Size)
+		add(categories, category, node.Size)
+	})
+
+	return &models.TypeBreakdown{
+		Extensions: sortedStats(extensions),
+		Categories: sortedStats(categories),
+	}
+}
+
+func (a *FileTypeAnalyzer) sniff(path string) string {
+	f, err := a.fs.Open(path)
+	if err != nil {
+		return CategoryOther
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(f, head)
+	if err != nil && n == 0 {
+		return CategoryOther
+	}
+	return CategoryForContent(head[:n])
+}
+
+func CategoryForExtension(ext string) string {
+	if category, ok := extensionCategories[ext]; ok {
+		return category
+	}
+	return CategoryOther
+}
+
+var magicCategories = []struct {
+	offset   int
+	magic    []byte
+	category string
+}{
+	{0, []byte("\x7fELF"), CategoryBinaries},
+	{0, []byte("MZ"), CategoryBinaries},
+	{0, []byte{0xcf, 0xfa, 0xed, 0xfe}, CategoryBinaries},
+	{0, []byte{0xce, 0xfa, 0xed, 0xfe}, CategoryBinaries},
+	{0, []byte{0xca, 0xfe, 0xba, 0xbe}, CategoryBinaries},
+	{0, []byte("\x00asm"), CategoryBinaries},
+	{0, []byte("QFI\xfb"), CategoryDiskImages},
+	{0, []byte("KDMV"), CategoryDiskImages},
+	{0, []byte("conectix"), CategoryDiskImages},
+	{0, []byte("vhdxfile"), CategoryDiskImages},
+	{0, []byte("\x1f\x8b"), CategoryArchives},
+	{0, []byte("BZh"), CategoryArchives},
+	{0, []byte("\xfd7zXZ\x00"), CategoryArchives},
+	{0, []byte("7z\xbc\xaf\x27\x1c"), CategoryArchives},
+	{0, []byte("\x28\xb5\x2f\xfd"), CategoryArchives},
+	{0, []byte("Rar!"), CategoryArchives},
+	{0, []byte("PK\x03\x04"), CategoryArchives},
+	{257, []byte("ustar"), CategoryArchives},
+	{4, []byte("ftyp"), CategoryVideo},
+}
+
+// CategoryForContent classifies data by well-known magic numbers, falling
+// back to MIME sniffing.
+func CategoryForContent(head []byte) string {
+	for _, m := range magicCategories {
+		if len(head) >= m.offset+len(m.magic) && bytes.Equal(head[m.offset:m.offset+len(m.magic)], m.magic) {
+			return m.category
+		}
+	}
+
+	mime := http.DetectContentType(head)
+	switch {
+	case strings.HasPrefix(mime, "video/"):
+		return CategoryVideo
+	case strings.HasPrefix(mime, "image/"):
+		return CategoryImages
+	case strings.HasPrefix(mime, "audio/"):
+		return CategoryAudio
+	case mime == "application/pdf", mime == "application/postscript":
+		return CategoryDocuments
+	case strings.HasPrefix(mime, "text/html"), strings.HasPrefix(mime, "text/xml"):
+		return CategoryCode
+	default:
+		return CategoryOther
+	}
+}
+
+func sortedStats(stats map[string]*models.TypeStat) []*models.TypeStat {
+	sorted := make([]*models.TypeStat, 0, len(stats))
+	for _, stat := range stats {
+		sorted = append(sorted, stat)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Size != sorted[j].Size {
+			return sorted[i].Size > sorted[j].Size
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"testing"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/vfs"
+)
+
+func statFor(stats []*models.TypeStat, key string) *models.TypeStat {
+	for _, stat := range stats {
+		if stat.Key == key {
+			return stat
+		}
+	}
+	return nil
+}
+
+func TestFileTypeAnalyzer_Analyze(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/movie.MP4", make([]byte, 1000))
+	_ = m.WriteFile("/data/clip.mov", make([]byte, 500))
+	_ = m.WriteFile("/data/app.log", make([]byte, 200))
+	_ = m.WriteFile("/data/main.go", make([]byte, 50))
+	_ = m.WriteFile("/data/Makefile", make([]byte, 10))
+	_ = m.WriteFile("/data/program", append([]byte("\x7fELF"), make([]byte, 60)...))
+	result := scanMemFS(t, m, "/data")
+
+	breakdown := NewFileTypeAnalyzer(m, nil).Analyze(result.Root)
+
+	if video := statFor(breakdown.Categories, CategoryVideo); video == nil || video.Size != 1500 || video.Count != 2 {
+		t.Errorf("video = %+v, want 1500 bytes in 2 files", video)
+	}
+	if mp4 := statFor(breakdown.Extensions, "mp4"); mp4 == nil || mp4.Size != 1000 {
+		t.Errorf("mp4 = %+v, want lower-cased extension with 1000 bytes", mp4)
+	}
+	if none := statFor(breakdown.Extensions, noExtension); none == nil || none.Count != 2 {
+		t.Errorf("no extension = %+v, want 2 files", none)
+	}
+	if other := statFor(breakdown.Categories, CategoryOther); other == nil || other.Count != 2 {
+		t.Errorf("other = %+v, want 2 files without sniffing", other)
+	}
+	if breakdown.Categories[0].Key != CategoryVideo {
+		t.Errorf("Categories[0] = %s, want largest category first", breakdown.Categories[0].Key)
+	}
+
+	sniffed := NewFileTypeAnalyzer(m, &FileTypeOptions{SniffContent: true}).Analyze(result.Root)
+	if binaries := statFor(sniffed.Categories, CategoryBinaries); binaries == nil || binaries.Count != 1 {
+		t.Errorf("binaries = %+v, want ELF file detected by content", binaries)
+	}
+}
+
+func TestCategoryForContent(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"elf", []byte("\x7fELF\x02\x01"), CategoryBinaries},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00"), CategoryImages},
+		{"pdf", []byte("%PDF-1.7\n"), CategoryDocuments},
+		{"qcow2", []byte("QFI\xfb\x00\x00\x00\x03"), CategoryDiskImages},
+		{"text", []byte("just some text"), CategoryOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CategoryForContent(tt.head); got != tt.want {
+				t.Errorf("CategoryForContent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package analyzer
+
+import (
+	"sort"
+
+	"vizdisk/internal/models"
+)
+
+type BrokenLink struct {
+	Path   string `json:"path"`
+	Target string `json:"target"`
+}
+
+// FindBrokenLinks lists the dangling symlinks kept by the scanner, sorted by
+// path.
+func FindBrokenLinks(root *models.FileNode) []*BrokenLink {
+	links := []*BrokenLink{}
+	walkFiles(root, func(node *models.FileNode) {
+		if node.IsBrokenLink {
+			links = append(links, &BrokenLink{Path: node.Path, Target: node.LinkTarget})
+		}
+	})
+	sort.Slice(links, func(i, j int) bool { return links[i].Path < links[j].Path })
+	return links
+}
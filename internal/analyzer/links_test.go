@@ -0,0 +1,33 @@
+package analyzer
+
+import (
+	"testing"
+
+	"vizdisk/internal/vfs"
+)
+
+func TestFindBrokenLinks(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/real.txt", []byte("x"))
+	_ = m.Symlink("real.txt", "/data/ok")
+	_ = m.Symlink("/opt/uninstalled/bin/tool", "/data/bin/tool")
+	_ = m.Symlink("b", "/data/a")
+	_ = m.Symlink("a", "/data/b")
+
+	result := scanMemFS(t, m, "/data")
+	links := FindBrokenLinks(result.Root)
+
+	want := []BrokenLink{{"/data/a", "b"}, {"/data/b", "a"}, {"/data/bin/tool", "/opt/uninstalled/bin/tool"}}
+	if len(links) != len(want) {
+		t.Fatalf("links = %+v, want %d", links, len(want))
+	}
+	for i, w := range want {
+		if *links[i] != w {
+			t.Errorf("links[%d] = %+v, want %+v", i, links[i], w)
+		}
+	}
+
+	if empty := FindEmpty(result.Root); len(empty.Files) != 0 {
+		t.Errorf("FindEmpty Files = %v, broken links are not zero-byte files", empty.Files)
+	}
+}
@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"sort"
+
+	"vizdisk/internal/models"
+)
+
+const unknownOwner = "(unknown)"
+
+type OwnerStat struct {
+	Name  string `json:"name"`
+	Size  int64  `json:"size"`
+	Count int64  `json:"count"`
+}
+
+type OwnershipReport struct {
+	Users  []*OwnerStat `json:"users"`
+	Groups []*OwnerStat `json:"groups"`
+}
+
+// AnalyzeOwnership sums file sizes and counts per owning user and group.
+func AnalyzeOwnership(root *models.FileNode) *OwnershipReport {
+	users := make(map[string]*OwnerStat)
+	groups := make(map[string]*OwnerStat)
+
+	add := func(stats map[string]*OwnerStat, name string, size int64) {
+		if name == "" {
+			name = unknownOwner
+		}
+		stat, ok := stats[name]
+		if !ok {
+			stat = &OwnerStat{Name: name}
+			stats[name] = stat
+		}
+		stat.Size += size
+		stat.Count++
+	}
+
+	walkFiles(root, func(node *models.FileNode) {
+		add(users, node.Owner, node.Size)
+		add(groups, node.Group, node.Size)
+	})
+
+	return &OwnershipReport{
+		Users:  sortedOwnerStats(users),
+		Groups: sortedOwnerStats(groups),
+	}
+}
+
+// FilterByOwner returns a copy of the tree holding only files owned by owner
+// and, if group is not empty, by group. Directories left without matching
+// files are dropped and directory sizes are recomputed.
+func FilterByOwner(root *models.FileNode, owner, group string) *models.FileNode {
+	return models.Filter(root, func(node *models.FileNode) bool {
+		return (owner == "" || node.Owner == owner) && (group == "" || node.Group == group)
+	})
+}
+
+func sortedOwnerStats(stats map[string]*OwnerStat) []*OwnerStat {
+	sorted := make([]*OwnerStat, 0, len(stats))
+	for _, stat := range stats {
+		sorted = append(sorted, stat)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Size != sorted[j].Size {
+			return sorted[i].Size > sorted[j].Size
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
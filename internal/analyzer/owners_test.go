@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"testing"
+
+	"vizdisk/internal/vfs"
+)
+
+func TestAnalyzeOwnership(t *testing.T) {
+	m := vfs.NewMemFS()
+	m.AddUser(1000, "alice")
+	m.AddUser(1001, "bob")
+	m.AddGroup(100, "users")
+
+	_ = m.WriteFile("/srv/alice/data.bin", make([]byte, 500))
+	_ = m.WriteFile("/srv/alice/notes.txt", make([]byte, 20))
+	_ = m.WriteFile("/srv/bob/build.log", make([]byte, 300))
+	_ = m.WriteFile("/srv/shared/from-alice", make([]byte, 80))
+	_ = m.WriteFile("/srv/shared/orphan", make([]byte, 7))
+	_ = m.Chown("/srv/alice/data.bin", 1000, 100)
+	_ = m.Chown("/srv/alice/notes.txt", 1000, 100)
+	_ = m.Chown("/srv/bob/build.log", 1001, 100)
+	_ = m.Chown("/srv/shared/from-alice", 1000, 100)
+	_ = m.Chown("/srv/shared/orphan", 4242, 4242)
+	_ = m.SetAllocated("/srv/alice/data.bin", 4096)
+	_ = m.SetAllocated("/srv/bob/build.log", 4096)
+
+	result := scanMemFS(t, m, "/srv")
+	report := AnalyzeOwnership(result.Root)
+
+	if len(report.Users) != 3 {
+		t.Fatalf("Users = %d, want 3", len(report.Users))
+	}
+	if alice := report.Users[0]; alice.Name != "alice" || alice.Size != 600 || alice.Count != 3 {
+		t.Errorf("Users[0] = %+v, want alice with 600 bytes in 3 files", alice)
+	}
+	if orphan := report.Users[2]; orphan.Name != "4242" {
+		t.Errorf("Users[2] = %+v, want unresolved numeric owner", orphan)
+	}
+	if report.Groups[0].Name != "users" || report.Groups[0].Size != 900 {
+		t.Errorf("Groups[0] = %+v, want users with 900 bytes", report.Groups[0])
+	}
+
+	filtered := FilterByOwner(result.Root, "alice", "")
+	if filtered.Size != 600 || filtered.AllocatedSize != 4096 {
+		t.Errorf("filtered size = %d (%d allocated), want 600 (4096)", filtered.Size, filtered.AllocatedSize)
+	}
+	if len(filtered.Children) != 2 {
+		t.Errorf("filtered children = %d, want alice and shared", len(filtered.Children))
+	}
+	if result.Root.Size != 907 {
+		t.Errorf("original tree modified: size = %d", result.Root.Size)
+	}
+
+	if empty := FilterByOwner(result.Root, "nobody", ""); empty.Size != 0 || len(empty.Children) != 0 {
+		t.Errorf("FilterByOwner(nobody) = %+v, want empty root", empty)
+	}
+}
@@ -0,0 +1,166 @@
+package analyzer
+
+import (
+	"container/heap"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+const (
+	DirectorySizeCumulative = "cumulative"
+	DirectorySizeOwn        = "own"
+)
+
+// TopNOptions selects what TopN ranks. Extensions only filter files; the
+// other filters apply to files and directories alike. Zero values disable a
+// filter.
+type TopNOptions struct {
+	N              int       `json:"n"`
+	DirectorySize  string    `json:"directorySize"`
+	Extensions     []string  `json:"extensions"`
+	ModifiedBefore time.Time `json:"modifiedBefore"`
+	ModifiedAfter  time.Time `json:"modifiedAfter"`
+	PathPrefix     string    `json:"pathPrefix"`
+}
+
+type RankedNode struct {
+	Path         string    `json:"path"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+type TopNResult struct {
+	Files       []*RankedNode `json:"files"`
+	Directories []*RankedNode `json:"directories"`
+}
+
+func DefaultTopNOptions() *TopNOptions {
+	return &TopNOptions{
+		N:             100,
+		DirectorySize: DirectorySizeCumulative,
+	}
+}
+
+// TopN returns the largest files and directories in the tree. Each list is
+// kept in a bounded min-heap, so the walk costs O(n log N) instead of sorting
+// every node.
+func TopN(root *models.FileNode, options *TopNOptions) *TopNResult {
+	if options == nil {
+		options = DefaultTopNOptions()
+	}
+	if options.N <= 0 {
+		return &TopNResult{Files: []*RankedNode{}, Directories: []*RankedNode{}}
+	}
+
+	extensions := make(map[string]bool, len(options.Extensions))
+	for _, ext := range options.Extensions {
+		extensions[strings.ToLower(strings.TrimPrefix(ext, "."))] = true
+	}
+
+	files := &rankHeap{}
+	dirs := &rankHeap{}
+
+	var visit func(node *models.FileNode)
+	visit = func(node *models.FileNode) {
+		if node == nil || node.IsVirtual {
+			return
+		}
+
+		if node.Type == scanner.FileTypeFile {
+			if len(extensions) > 0 && !extensions[models.Extension(node.Name)] {
+				return
+			}
+			if matchesTopNFilters(node, options) {
+				files.offer(node, node.Size, options.N)
+			}
+			return
+		}
+
+		if matchesTopNFilters(node, options) {
+			size := node.Size
+			if options.DirectorySize == DirectorySizeOwn {
+				size = 0
+				for _, child := range node.Children {
+					if child.Type == scanner.FileTypeFile {
+						size += child.Size
+					}
+				}
+			}
+			dirs.offer(node, size, options.N)
+		}
+
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	visit(root)
+
+	return &TopNResult{
+		Files:       files.sorted(),
+		Directories: dirs.sorted(),
+	}
+}
+
+func matchesTopNFilters(node *models.FileNode, options *TopNOptions) bool {
+	if options.PathPrefix != "" && node.Path != options.PathPrefix && !isUnder(node.Path, options.PathPrefix) {
+		return false
+	}
+	if !options.ModifiedBefore.IsZero() && !node.LastModified.Before(options.ModifiedBefore) {
+		return false
+	}
+	if !options.ModifiedAfter.IsZero() && !node.LastModified.After(options.ModifiedAfter) {
+		return false
+	}
+	return true
+}
+
+// rankHeap is a min-heap on size holding the N largest nodes seen so far.
+type rankHeap []*RankedNode
+
+func (h rankHeap) Len() int           { return len(h) }
+func (h rankHeap) Less(i, j int) bool { return h[i].Size < h[j].Size }
+func (h rankHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *rankHeap) Push(x any)        { *h = append(*h, x.(*RankedNode)) }
+
+func (h *rankHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+func (h *rankHeap) offer(node *models.FileNode, size int64, limit int) {
+	if h.Len() >= limit && size <= (*h)[0].Size {
+		return
+	}
+
+	heap.Push(h, &RankedNode{
+		Path:         node.Path,
+		Name:         filepath.Base(node.Path),
+		Type:         node.Type,
+		Size:         size,
+		LastModified: node.LastModified,
+	})
+	if h.Len() > limit {
+		heap.Pop(h)
+	}
+}
+
+func (h *rankHeap) sorted() []*RankedNode {
+	sorted := append([]*RankedNode{}, (*h)...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Size != sorted[j].Size {
+			return sorted[i].Size > sorted[j].Size
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+	return sorted
+}
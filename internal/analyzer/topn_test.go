@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"vizdisk/internal/vfs"
+)
+
+func TestTopN(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := vfs.NewMemFS()
+	files := map[string]int{
+		"/data/videos/a.mp4":      900,
+		"/data/videos/b.mov":      700,
+		"/data/videos/c.mp4":      100,
+		"/data/logs/app.log":      800,
+		"/data/logs/old/x.log":    50,
+		"/data/src/main.go":       30,
+		"/data/src/vendor/big.go": 600,
+	}
+	for path, size := range files {
+		_ = m.WriteFile(path, make([]byte, size))
+		_ = m.Chtimes(path, now)
+	}
+	_ = m.Chtimes("/data/videos/b.mov", now.AddDate(-2, 0, 0))
+
+	result := scanMemFS(t, m, "/data")
+
+	top := TopN(result.Root, &TopNOptions{N: 3, DirectorySize: DirectorySizeCumulative})
+	wantFiles := []string{"/data/videos/a.mp4", "/data/logs/app.log", "/data/videos/b.mov"}
+	if len(top.Files) != 3 {
+		t.Fatalf("Files = %d, want 3", len(top.Files))
+	}
+	for i, want := range wantFiles {
+		if top.Files[i].Path != want {
+			t.Errorf("Files[%d] = %s, want %s", i, top.Files[i].Path, want)
+		}
+	}
+	if top.Directories[0].Path != "/data" || top.Directories[1].Path != "/data/videos" {
+		t.Errorf("Directories = %s, %s, want /data then /data/videos", top.Directories[0].Path, top.Directories[1].Path)
+	}
+
+	own := TopN(result.Root, &TopNOptions{N: 2, DirectorySize: DirectorySizeOwn})
+	if own.Directories[0].Path != "/data/videos" || own.Directories[1].Path != "/data/logs" {
+		t.Errorf("own size Directories = %s, %s, want videos then logs", own.Directories[0].Path, own.Directories[1].Path)
+	}
+
+	filtered := TopN(result.Root, &TopNOptions{N: 10, Extensions: []string{".MP4", "mov"}, ModifiedAfter: now.AddDate(-1, 0, 0)})
+	if len(filtered.Files) != 2 || filtered.Files[0].Path != "/data/videos/a.mp4" {
+		t.Errorf("filtered Files = %+v, want the two recent mp4 files", filtered.Files)
+	}
+
+	prefixed := TopN(result.Root, &TopNOptions{N: 10, PathPrefix: "/data/src"})
+	if len(prefixed.Files) != 2 || len(prefixed.Directories) != 2 {
+		t.Errorf("prefixed = %d files, %d dirs, want 2 and 2", len(prefixed.Files), len(prefixed.Directories))
+	}
+}
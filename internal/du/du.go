@@ -0,0 +1,250 @@
+// Package du turns the text output of `du -a` into a file tree, for
+// machines where du is the only tool available.
+//
+// Each line holds a size and a path separated by a tab, optionally with a
+// modification time in between (`du --time`). Lines may come in any order,
+// so output piped through sort or grep works too. Directories are inferred
+// from the paths: anything with entries beneath it is a directory, and
+// missing directories in between are created. An empty directory is
+// indistinguishable from a file and is imported as one.
+package du
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+// Options describe how the output was produced.
+type Options struct {
+	// BlockSize is the number of bytes per unit of the size column: 1024
+	// for `du -a` and `du -ak` and 1 for `du -ab`. Sizes with a unit
+	// suffix, as printed by `du -h`, ignore it. Zero means 1024.
+	BlockSize int64 `json:"blockSize"`
+	// Root is the directory du ran in. Relative paths are resolved against
+	// it when set; without it, paths leading out of it with ".." are
+	// rejected.
+	Root string `json:"root"`
+}
+
+type line struct {
+	size     int64
+	modified time.Time
+}
+
+// Parse reads du output into a scan result. Sizes of listed files are taken
+// as they are; directory sizes are recomputed from their contents. Unless
+// the output is in bytes, the sizes are disk usage and are also reported as
+// the allocated size.
+func Parse(r io.Reader, options *Options) (*models.ScanResult, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	blockSize := options.BlockSize
+	if blockSize <= 0 {
+		blockSize = 1024
+	}
+
+	lines := make(map[string]*line)
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; s.Scan(); n++ {
+		text := strings.TrimRight(s.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		p, l, err := parseLine(text, blockSize)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if options.Root != "" && !path.IsAbs(p) {
+			p = path.Join(options.Root, p)
+		}
+		p = path.Clean(p)
+		if p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("line %d: %s is outside the directory du ran in; set the root directory to import it", n, p)
+		}
+		lines[p] = l
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no du output found")
+	}
+
+	var rootPath string
+	for p := range lines {
+		if rootPath = commonAncestor(rootPath, p); rootPath == "" {
+			return nil, fmt.Errorf("du output mixes absolute and relative paths")
+		}
+	}
+	root := build(lines, rootPath, blockSize > 1)
+	return scanner.NewResult(root, time.Now()), nil
+}
+
+// parseLine splits a line into its path and size, with the optional time
+// column of `du --time`.
+func parseLine(text string, blockSize int64) (string, *line, error) {
+	fields := strings.SplitN(text, "\t", 3)
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("expected a size and a path separated by a tab: %q", text)
+	}
+	size, err := parseSize(strings.TrimSpace(fields[0]), blockSize)
+	if err != nil {
+		return "", nil, err
+	}
+
+	l := &line{size: size}
+	p := fields[1]
+	if len(fields) == 3 {
+		if l.modified, err = parseTime(fields[1]); err != nil {
+			return "", nil, err
+		}
+		p = fields[2]
+	}
+	if p == "" {
+		return "", nil, fmt.Errorf("empty path")
+	}
+	return p, l, nil
+}
+
+// parseSize accepts plain counts of blocks and the suffixed sizes of du -h.
+func parseSize(s string, blockSize int64) (int64, error) {
+	units := "KMGTPE"
+	if s != "" {
+		if i := strings.IndexByte(units, s[len(s)-1]); i >= 0 {
+			value, err := strconv.ParseFloat(s[:len(s)-1], 64)
+			if err != nil || value < 0 {
+				return 0, fmt.Errorf("invalid size %q", s)
+			}
+			for range i + 1 {
+				value *= 1024
+			}
+			return int64(value), nil
+		}
+	}
+	value, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return value * blockSize, nil
+}
+
+// parseTime reads the formats of du --time and --time-style=full-iso/iso.
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05.999999999 -0700", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// build links the listed paths into a tree below their deepest common
+// ancestor.
+func build(lines map[string]*line, rootPath string, allocated bool) *models.FileNode {
+	nodes := make(map[string]*models.FileNode, len(lines))
+	get := func(p string) *models.FileNode {
+		node, ok := nodes[p]
+		if !ok {
+			node = &models.FileNode{
+				ID:       scanner.GenerateID(p),
+				Name:     path.Base(p),
+				Path:     p,
+				Type:     scanner.FileTypeFile,
+				IsHidden: strings.HasPrefix(path.Base(p), ".") && path.Base(p) != "." && path.Base(p) != "..",
+			}
+			nodes[p] = node
+		}
+		return node
+	}
+
+	for p, l := range lines {
+		node := get(p)
+		node.Size = l.size
+		node.LastModified = l.modified
+		if allocated {
+			node.AllocatedSize = l.size
+		}
+	}
+
+	// Link every listed path up to the root, stopping at the first
+	// ancestor that is already linked
+	linked := make(map[string]bool, len(lines))
+	for p := range lines {
+		for p != rootPath && !linked[p] {
+			parent := get(path.Dir(p))
+			parent.Type = scanner.FileTypeDirectory
+			parent.Children = append(parent.Children, get(p))
+			linked[p] = true
+			p = parent.Path
+		}
+	}
+
+	root := get(rootPath)
+	finish(root)
+	return root
+}
+
+// finish sorts children by name like a directory listing and sums directory
+// sizes.
+func finish(node *models.FileNode) {
+	if node.Type != scanner.FileTypeDirectory {
+		return
+	}
+	sort.Slice(node.Children, func(i, j int) bool { return node.Children[i].Name < node.Children[j].Name })
+	node.Size, node.AllocatedSize = 0, 0
+	for _, child := range node.Children {
+		finish(child)
+		node.Size += child.Size
+		node.AllocatedSize += child.AllocatedSize
+	}
+	if node.Children == nil {
+		node.Children = []*models.FileNode{}
+	}
+}
+
+// commonAncestor returns the deepest path that is or contains both a and b,
+// or "" if one is absolute and the other relative. Relative paths share ".".
+func commonAncestor(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if contains(a, b) {
+		return a
+	}
+	ancestors := map[string]bool{}
+	for p := a; ; p = path.Dir(p) {
+		ancestors[p] = true
+		if p == "." || p == "/" {
+			break
+		}
+	}
+	for p := b; ; p = path.Dir(p) {
+		if ancestors[p] {
+			return p
+		}
+		if p == "." || p == "/" {
+			return ""
+		}
+	}
+}
+
+func contains(dir, p string) bool {
+	switch dir {
+	case ".":
+		return !path.IsAbs(p) && p != ".." && !strings.HasPrefix(p, "../")
+	case "/":
+		return path.IsAbs(p)
+	}
+	return p == dir || strings.HasPrefix(p, dir+"/")
+}
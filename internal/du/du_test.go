@@ -0,0 +1,137 @@
+package du
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+func find(node *models.FileNode, p string) *models.FileNode {
+	if node.Path == p {
+		return node
+	}
+	for _, child := range node.Children {
+		if found := find(child, p); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func TestParse_Bytes(t *testing.T) {
+	output := "3\t./top.txt\n" +
+		"100\t./.h/x\n" +
+		"4196\t./.h\n" +
+		"5000\t./a/b/f1\n" +
+		"9096\t./a/b\n" +
+		"13192\t./a\n" +
+		"21487\t.\n"
+
+	result, err := Parse(strings.NewReader(output), &Options{BlockSize: 1})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if result.Root.Path != "." || result.TotalSize != 5103 || result.TotalFiles != 3 || result.TotalDirectories != 4 {
+		t.Errorf("root %s: %d bytes, %d files, %d dirs", result.Root.Path, result.TotalSize, result.TotalFiles, result.TotalDirectories)
+	}
+
+	b := find(result.Root, "a/b")
+	if b == nil || b.Type != scanner.FileTypeDirectory || b.Size != 5000 || len(b.Children) != 1 {
+		t.Errorf("a/b = %+v", b)
+	}
+	if h := find(result.Root, ".h"); h == nil || !h.IsHidden {
+		t.Errorf(".h = %+v, want hidden directory", h)
+	}
+	if f := find(result.Root, "a/b/f1"); f == nil || f.AllocatedSize != 0 || f.ID != scanner.GenerateID("a/b/f1") {
+		t.Errorf("f1 = %+v, apparent sizes should not be reported as allocated", f)
+	}
+	if names := []string{result.Root.Children[0].Name, result.Root.Children[1].Name}; names[0] != ".h" || names[1] != "a" {
+		t.Errorf("children not sorted by name: %v", names)
+	}
+}
+
+func TestParse_KilobytesWithTime(t *testing.T) {
+	output := "4\t2026-10-19 05:14\t/tmp/dut/top.txt\n" +
+		"8\t2026-10-19 05:14\t/tmp/dut/a/b/f1\n" +
+		"12\t2026-10-19 05:14\t/tmp/dut/a/b\n" +
+		"32\t2026-10-19 05:14\t/tmp/dut\n"
+
+	result, err := Parse(strings.NewReader(output), nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	f1 := find(result.Root, "/tmp/dut/a/b/f1")
+	if f1 == nil || f1.Size != 8192 || f1.AllocatedSize != 8192 {
+		t.Fatalf("f1 = %+v", f1)
+	}
+	if want := time.Date(2026, 10, 19, 5, 14, 0, 0, time.Local); !f1.LastModified.Equal(want) {
+		t.Errorf("f1 modified = %v, want %v", f1.LastModified, want)
+	}
+	// /tmp/dut/a was not listed but is created from the path
+	if a := find(result.Root, "/tmp/dut/a"); a == nil || a.Type != scanner.FileTypeDirectory || a.Size != 8192 {
+		t.Errorf("a = %+v", a)
+	}
+}
+
+func TestParse_SortedHumanReadable(t *testing.T) {
+	// du -ah . | sort -h, without the root line
+	output := "4.0K\t./.h/x\n4.0K\t./top.txt\n8.0K\t./.h\n1.5M\t./a/b/f1\n1.6M\t./a\n"
+
+	result, err := Parse(strings.NewReader(output), &Options{Root: "/home/me"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if result.Root.Path != "/home/me" {
+		t.Errorf("root = %s, want /home/me", result.Root.Path)
+	}
+	if f1 := find(result.Root, "/home/me/a/b/f1"); f1 == nil || f1.Size != 1572864 {
+		t.Errorf("f1 = %+v", f1)
+	}
+	if result.TotalSize != 4096+4096+1572864 {
+		t.Errorf("TotalSize = %d", result.TotalSize)
+	}
+}
+
+func TestParse_ParentPathsWithRoot(t *testing.T) {
+	output := "4\t../x/a\n8\t./b\n"
+
+	result, err := Parse(strings.NewReader(output), &Options{Root: "/home/me/work"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if result.Root.Path != "/home/me" {
+		t.Errorf("root = %s, want /home/me", result.Root.Path)
+	}
+	if a := find(result.Root, "/home/me/x/a"); a == nil || a.Size != 4096 {
+		t.Errorf("a = %+v", a)
+	}
+	if b := find(result.Root, "/home/me/work/b"); b == nil || b.Size != 8192 {
+		t.Errorf("b = %+v", b)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"empty", "\n\n", "no du output"},
+		{"no tab", "12 ./a\n", "line 1"},
+		{"bad size", "4\t./a\nlots\t./b\n", "line 2: invalid size"},
+		{"bad time", "4\tyesterday\t./a\n", "invalid time"},
+		{"mixed paths", "4\t/a\n4\tb\n", "mixes absolute and relative"},
+		{"above the root", "4\t../x/a\n8\t./b\n", "line 1: ../x/a is outside"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.output), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+// Package export writes scan results in formats other tools read: flat CSV
+// for spreadsheets, nested JSON, and NDJSON with one node per line for jq
+// and log pipelines. All three are written while the tree is walked.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/query"
+	"vizdisk/internal/scanner"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// Options limit what is exported. Zero values export everything.
+type Options struct {
+	// MaxDepth is how many levels below the root are written
+	MaxDepth int `json:"maxDepth"`
+	// MinSize drops files and directories smaller than this many bytes,
+	// along with everything beneath them
+	MinSize int64 `json:"minSize"`
+	// Filter is a query language expression; only matching files and the
+	// directories leading to them are exported
+	Filter string `json:"filter"`
+}
+
+// Record is one node in the flat formats.
+type Record struct {
+	Path      string    `json:"path"`
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	Allocated int64     `json:"allocated"`
+	Type      string    `json:"type"`
+	Modified  time.Time `json:"mtime"`
+	Owner     string    `json:"owner,omitempty"`
+	Group     string    `json:"group,omitempty"`
+	Depth     int       `json:"depth"`
+}
+
+// FormatForPath picks the format from a file extension.
+func FormatForPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("unsupported export format: %s", path)
+}
+
+// Write exports result in the given format.
+func Write(w io.Writer, result *models.ScanResult, format string, options *Options) error {
+	switch format {
+	case FormatCSV:
+		return CSV(w, result, options)
+	case FormatJSON:
+		return JSON(w, result, options)
+	case FormatNDJSON:
+		return NDJSON(w, result, options)
+	}
+	return fmt.Errorf("unknown export format: %s", format)
+}
+
+// CSV writes a header row and then one row per node in tree order with the
+// columns path, size, allocated, type, mtime and owner.
+func CSV(w io.Writer, result *models.ScanResult, options *Options) error {
+	result, options, err := prepare(result, options)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"path", "size", "allocated", "type", "mtime", "owner"}); err != nil {
+		return err
+	}
+	err = walk(result.Root, options, func(r *Record) error {
+		mtime := ""
+		if !r.Modified.IsZero() {
+			mtime = r.Modified.Format(time.RFC3339)
+		}
+		return cw.Write([]string{
+			r.Path,
+			strconv.FormatInt(r.Size, 10),
+			strconv.FormatInt(r.Allocated, 10),
+			r.Type,
+			mtime,
+			r.Owner,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// NDJSON writes one Record object per line in tree order.
+func NDJSON(w io.Writer, result *models.ScanResult, options *Options) error {
+	result, options, err := prepare(result, options)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+	if err := walk(result.Root, options, func(r *Record) error { return encoder.Encode(r) }); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// jsonTotals is models.ScanResult without its root.
+type jsonTotals struct {
+	TotalSize        int64                 `json:"totalSize"`
+	TotalFiles       int64                 `json:"totalFiles"`
+	TotalDirectories int64                 `json:"totalDirectories"`
+	ScanTime         time.Time             `json:"scanTime"`
+	ScanDurationMs   int64                 `json:"scanDuration"`
+	FileTypes        *models.TypeBreakdown `json:"fileTypes,omitempty"`
+}
+
+// JSON writes the scan totals and the tree nested like models.ScanResult.
+func JSON(w io.Writer, result *models.ScanResult, options *Options) error {
+	result, options, err := prepare(result, options)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	data, err := json.Marshal(&jsonTotals{
+		TotalSize:        result.TotalSize,
+		TotalFiles:       result.TotalFiles,
+		TotalDirectories: result.TotalDirectories,
+		ScanTime:         result.ScanTime,
+		ScanDurationMs:   result.ScanDurationMs,
+		FileTypes:        result.FileTypes,
+	})
+	if err != nil {
+		return err
+	}
+	// The tree follows as the last field instead of being marshalled whole
+	bw.Write(data[:len(data)-1])
+	bw.WriteString(`,"root":`)
+	if err := writeNode(bw, result.Root, 0, options); err != nil {
+		return err
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// writeNode writes node and its children, which are spliced into the
+// marshalled node in place of the omitted children field.
+func writeNode(w *bufio.Writer, node *models.FileNode, depth int, options *Options) error {
+	copied := *node
+	copied.Children = nil
+	data, err := json.Marshal(&copied)
+	if err != nil {
+		return err
+	}
+
+	children := exportedChildren(node, depth, options)
+	if len(children) == 0 {
+		_, err := w.Write(data)
+		return err
+	}
+	w.Write(data[:len(data)-1])
+	w.WriteString(`,"children":[`)
+	for i, child := range children {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if err := writeNode(w, child, depth+1, options); err != nil {
+			return err
+		}
+	}
+	_, err = w.WriteString("]}")
+	return err
+}
+
+// prepare applies the filter expression, which also recomputes the totals.
+func prepare(result *models.ScanResult, options *Options) (*models.ScanResult, *Options, error) {
+	if result == nil || result.Root == nil {
+		return nil, nil, fmt.Errorf("no scan result to export")
+	}
+	if options == nil {
+		options = &Options{}
+	}
+	if options.Filter == "" {
+		return result, options, nil
+	}
+
+	match, err := query.Compile(options.Filter, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	filtered := scanner.NewResult(models.Filter(result.Root, match), result.ScanTime)
+	filtered.ScanDurationMs = result.ScanDurationMs
+	return filtered, options, nil
+}
+
+// walk calls fn for the root and every exported node beneath it, parents
+// before children.
+func walk(root *models.FileNode, options *Options, fn func(*Record) error) error {
+	var visit func(node *models.FileNode, depth int) error
+	visit = func(node *models.FileNode, depth int) error {
+		err := fn(&Record{
+			Path:      node.Path,
+			Name:      node.Name,
+			Size:      node.Size,
+			Allocated: node.AllocatedSize,
+			Type:      node.Type,
+			Modified:  node.LastModified,
+			Owner:     node.Owner,
+			Group:     node.Group,
+			Depth:     depth,
+		})
+		if err != nil {
+			return err
+		}
+		for _, child := range exportedChildren(node, depth, options) {
+			if err := visit(child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return visit(root, 0)
+}
+
+// exportedChildren returns the children of node that pass the depth and
+// size limits. Entries inside archives are left out, as they take no space
+// of their own.
+func exportedChildren(node *models.FileNode, depth int, options *Options) []*models.FileNode {
+	if node.Type != scanner.FileTypeDirectory || (options.MaxDepth > 0 && depth >= options.MaxDepth) {
+		return nil
+	}
+	var children []*models.FileNode
+	for _, child := range node.Children {
+		if child.IsVirtual || child.Size < options.MinSize {
+			continue
+		}
+		children = append(children, child)
+	}
+	return children
+}
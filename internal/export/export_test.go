@@ -0,0 +1,132 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+func scanTestTree(t *testing.T) *models.ScanResult {
+	t.Helper()
+	m := vfs.NewMemFS()
+	m.AddUser(501, "alice")
+	_ = m.WriteFile("/data/videos/holiday.mp4", make([]byte, 6000))
+	_ = m.WriteFile("/data/videos/raw/clip.mov", make([]byte, 3000))
+	_ = m.WriteFile("/data/docs/a,b.txt", make([]byte, 20))
+	_ = m.Chown("/data/videos/holiday.mp4", 501, 501)
+	_ = m.SetAllocated("/data/videos/holiday.mp4", 8192)
+	_ = m.Chtimes("/data/videos/holiday.mp4", time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC))
+
+	result, err := scanner.NewScannerWithFS(m, scanner.DefaultScanOptions()).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	return result
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := CSV(&buf, scanTestTree(t), nil); err != nil {
+		t.Fatalf("CSV() error = %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+
+	if strings.Join(rows[0], ",") != "path,size,allocated,type,mtime,owner" {
+		t.Errorf("header = %v", rows[0])
+	}
+	if len(rows) != 1+7 {
+		t.Errorf("rows = %d, want header and 7 nodes", len(rows))
+	}
+	if rows[1][0] != "/data" || rows[1][1] != "9020" {
+		t.Errorf("first row = %v, want the root", rows[1])
+	}
+
+	var holiday []string
+	for _, row := range rows {
+		if row[0] == "/data/videos/holiday.mp4" {
+			holiday = row
+		}
+	}
+	want := []string{"/data/videos/holiday.mp4", "6000", "8192", "file", time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC).Local().Format(time.RFC3339), "alice"}
+	if strings.Join(holiday, "|") != strings.Join(want, "|") {
+		t.Errorf("holiday row = %v, want %v", holiday, want)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NDJSON(&buf, scanTestTree(t), &Options{MaxDepth: 1, MinSize: 100}); err != nil {
+		t.Fatalf("NDJSON() error = %v", err)
+	}
+
+	var paths []string
+	lines := bufio.NewScanner(&buf)
+	for lines.Scan() {
+		var r Record
+		if err := json.Unmarshal(lines.Bytes(), &r); err != nil {
+			t.Fatalf("line %q: %v", lines.Text(), err)
+		}
+		paths = append(paths, r.Path)
+	}
+	// docs is below MinSize, and depth 1 stops at /data/videos
+	if got := strings.Join(paths, " "); got != "/data /data/videos" {
+		t.Errorf("paths = %s", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	result := scanTestTree(t)
+
+	var buf bytes.Buffer
+	if err := JSON(&buf, result, nil); err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	var decoded models.ScanResult
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not a ScanResult: %v\n%s", err, buf.String())
+	}
+	want, _ := json.Marshal(result)
+	got, _ := json.Marshal(&decoded)
+	if string(got) != string(want) {
+		t.Errorf("unfiltered export differs from the scan\n got: %s\nwant: %s", got, want)
+	}
+
+	buf.Reset()
+	if err := JSON(&buf, result, &Options{Filter: "ext = mov"}); err != nil {
+		t.Fatalf("JSON() with filter error = %v", err)
+	}
+	decoded = models.ScanResult{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.TotalSize != 3000 || decoded.TotalFiles != 1 || len(decoded.Root.Children) != 1 {
+		t.Errorf("filtered export = %d bytes in %d files", decoded.TotalSize, decoded.TotalFiles)
+	}
+}
+
+func TestWrite_Errors(t *testing.T) {
+	result := scanTestTree(t)
+	if err := Write(&bytes.Buffer{}, result, "xml", nil); err == nil {
+		t.Error("unknown format should fail")
+	}
+	if err := Write(&bytes.Buffer{}, result, FormatCSV, &Options{Filter: "size >"}); err == nil {
+		t.Error("invalid filter should fail")
+	}
+	if _, err := FormatForPath("out.xlsx"); err == nil {
+		t.Error("FormatForPath(.xlsx) should fail")
+	}
+	if format, _ := FormatForPath("out.JSONL"); format != FormatNDJSON {
+		t.Errorf("FormatForPath(.JSONL) = %q", format)
+	}
+}
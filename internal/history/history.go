@@ -0,0 +1,271 @@
+// Package history records directory sizes from every scan so that their
+// growth can be charted over time.
+//
+// Each scan stores one entry per directory down to a configurable depth in
+// a bbolt file. Size entries are keyed by path, then scan time, so a time
+// series is a single prefix scan. Paths are absolute, which means a scan of
+// ~/Projects and a scan of ~ both contribute points to ~/Projects.
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+var (
+	scansBucket = []byte("scans")
+	sizesBucket = []byte("sizes")
+)
+
+type RecordOptions struct {
+	// MaxDepth is how many levels below the scanned root are recorded; 0
+	// records only the root
+	MaxDepth int `json:"maxDepth"`
+}
+
+func DefaultRecordOptions() *RecordOptions {
+	return &RecordOptions{MaxDepth: 3}
+}
+
+// Scan describes one recorded scan.
+type Scan struct {
+	ID          uint64    `json:"id"`
+	Path        string    `json:"path"`
+	ScanTime    time.Time `json:"scanTime"`
+	TotalSize   int64     `json:"totalSize"`
+	TotalFiles  int64     `json:"totalFiles"`
+	MaxDepth    int       `json:"maxDepth"`
+	Directories int       `json:"directories"`
+}
+
+// Point is the size of one directory at the time of one scan.
+type Point struct {
+	ScanID uint64    `json:"scanId"`
+	Time   time.Time `json:"time"`
+	Size   int64     `json:"size"`
+	Files  int64     `json:"files"`
+}
+
+type DB struct {
+	db *bolt.DB
+}
+
+// Open opens the history database at path, creating it if needed. Only one
+// process can hold it open at a time.
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(scansBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(sizesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+func (h *DB) Close() error {
+	return h.db.Close()
+}
+
+// Record stores the directory sizes of result as a new scan.
+func (h *DB) Record(result *models.ScanResult, options *RecordOptions) (*Scan, error) {
+	if result == nil || result.Root == nil {
+		return nil, fmt.Errorf("no scan result to record")
+	}
+	if options == nil {
+		options = DefaultRecordOptions()
+	}
+
+	scan := &Scan{
+		Path:       filepath.Clean(result.Root.Path),
+		ScanTime:   result.ScanTime,
+		TotalSize:  result.TotalSize,
+		TotalFiles: result.TotalFiles,
+		MaxDepth:   options.MaxDepth,
+	}
+	if scan.ScanTime.IsZero() {
+		scan.ScanTime = time.Now()
+	}
+	err := h.db.Update(func(tx *bolt.Tx) error {
+		scans := tx.Bucket(scansBucket)
+		id, err := scans.NextSequence()
+		if err != nil {
+			return err
+		}
+		scan.ID = id
+
+		sizes := tx.Bucket(sizesBucket)
+		var visit func(node *models.FileNode, depth int) (int64, error)
+		visit = func(node *models.FileNode, depth int) (int64, error) {
+			if node.Type != scanner.FileTypeDirectory {
+				return 1, nil
+			}
+			var files int64
+			for _, child := range node.Children {
+				if child.IsVirtual {
+					continue
+				}
+				n, err := visit(child, depth+1)
+				if err != nil {
+					return 0, err
+				}
+				files += n
+			}
+			if depth <= options.MaxDepth {
+				scan.Directories++
+				value := binary.AppendVarint(nil, node.Size)
+				value = binary.AppendVarint(value, files)
+				if err := sizes.Put(sizeKey(filepath.Clean(node.Path), scan.ScanTime, id), value); err != nil {
+					return 0, err
+				}
+			}
+			return files, nil
+		}
+		if _, err := visit(result.Root, 0); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(scan)
+		if err != nil {
+			return err
+		}
+		return scans.Put(scanKey(id), data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scan, nil
+}
+
+// Scans lists the recorded scans, newest first.
+func (h *DB) Scans() ([]*Scan, error) {
+	scans := []*Scan{}
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(scansBucket).ForEach(func(_, value []byte) error {
+			var scan Scan
+			if err := json.Unmarshal(value, &scan); err != nil {
+				return err
+			}
+			scans = append(scans, &scan)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(scans, func(i, j int) bool { return scans[i].ScanTime.After(scans[j].ScanTime) })
+	return scans, nil
+}
+
+// Series returns the recorded sizes of the directory at path in time order.
+// Zero from or to leave that end of the range open.
+func (h *DB) Series(path string, from, to time.Time) ([]*Point, error) {
+	prefix := append([]byte(filepath.Clean(path)), 0)
+	points := []*Point{}
+	err := h.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(sizesBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			point, err := decodePoint(k[len(prefix):], v)
+			if err != nil {
+				return err
+			}
+			if (!from.IsZero() && point.Time.Before(from)) || (!to.IsZero() && point.Time.After(to)) {
+				continue
+			}
+			points = append(points, point)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// DeleteScan removes a recorded scan and its sizes. Size entries are keyed
+// by path, so this walks all of them.
+func (h *DB) DeleteScan(id uint64) error {
+	return h.db.Update(func(tx *bolt.Tx) error {
+		scans := tx.Bucket(scansBucket)
+		if scans.Get(scanKey(id)) == nil {
+			return fmt.Errorf("history scan %d not found", id)
+		}
+		if err := scans.Delete(scanKey(id)); err != nil {
+			return err
+		}
+
+		suffix := scanKey(id)
+		var keys [][]byte
+		sizes := tx.Bucket(sizesBucket)
+		err := sizes.ForEach(func(k, _ []byte) error {
+			if bytes.HasSuffix(k, suffix) {
+				keys = append(keys, bytes.Clone(k))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := sizes.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func scanKey(id uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, id)
+}
+
+// sizeKey orders entries by path, then scan time, then scan ID. The path is
+// terminated by a zero byte so that one path is never a prefix of another.
+func sizeKey(path string, scanTime time.Time, id uint64) []byte {
+	key := append([]byte(path), 0)
+	key = binary.BigEndian.AppendUint64(key, uint64(scanTime.UnixNano()))
+	return binary.BigEndian.AppendUint64(key, id)
+}
+
+func decodePoint(key, value []byte) (*Point, error) {
+	if len(key) != 16 {
+		return nil, errors.New("corrupt history entry")
+	}
+	point := &Point{
+		Time:   time.Unix(0, int64(binary.BigEndian.Uint64(key))),
+		ScanID: binary.BigEndian.Uint64(key[8:]),
+	}
+	size, n := binary.Varint(value)
+	if n <= 0 {
+		return nil, errors.New("corrupt history entry")
+	}
+	files, m := binary.Varint(value[n:])
+	if m <= 0 {
+		return nil, errors.New("corrupt history entry")
+	}
+	point.Size, point.Files = size, files
+	return point, nil
+}
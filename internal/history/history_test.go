@@ -0,0 +1,127 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+func scanAt(t *testing.T, files map[string]int, scanTime time.Time) *models.ScanResult {
+	t.Helper()
+	m := vfs.NewMemFS()
+	for path, size := range files {
+		_ = m.WriteFile(path, make([]byte, size))
+	}
+	result, err := scanner.NewScannerWithFS(m, scanner.DefaultScanOptions()).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	result.ScanTime = scanTime
+	return result
+}
+
+func TestRecordAndSeries(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "history", "history.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := jan.AddDate(0, 1, 0)
+	mar := jan.AddDate(0, 2, 0)
+
+	scans := []struct {
+		at    time.Time
+		files map[string]int
+	}{
+		{jan, map[string]int{"/data/logs/a.log": 100, "/data/src/deep/er/x.go": 10}},
+		{mar, map[string]int{"/data/logs/a.log": 400, "/data/logs/b.log": 100}},
+		// Recorded out of order, the series is still sorted by time
+		{feb, map[string]int{"/data/logs/a.log": 250, "/data/logs2/c.log": 5}},
+	}
+	var ids []uint64
+	for _, s := range scans {
+		scan, err := db.Record(scanAt(t, s.files, s.at), &RecordOptions{MaxDepth: 2})
+		if err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+		ids = append(ids, scan.ID)
+	}
+
+	points, err := db.Series("/data/logs", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Series() error = %v", err)
+	}
+	var sizes []int64
+	for _, p := range points {
+		sizes = append(sizes, p.Size)
+	}
+	if len(sizes) != 3 || sizes[0] != 100 || sizes[1] != 250 || sizes[2] != 500 {
+		t.Errorf("Series(/data/logs) sizes = %v, want [100 250 500]", sizes)
+	}
+	if points[2].Files != 2 || !points[2].Time.Equal(mar) || points[2].ScanID != ids[1] {
+		t.Errorf("last point = %+v", points[2])
+	}
+
+	// /data/logs is not a prefix match for /data/logs2
+	if points, _ := db.Series("/data/logs2/", time.Time{}, time.Time{}); len(points) != 1 {
+		t.Errorf("Series(/data/logs2) = %d points, want 1", len(points))
+	}
+
+	// Depth 2 covers /data/src/deep but not /data/src/deep/er
+	if points, _ := db.Series("/data/src/deep", time.Time{}, time.Time{}); len(points) != 1 || points[0].Size != 10 {
+		t.Errorf("Series(/data/src/deep) = %v", points)
+	}
+	if points, _ := db.Series("/data/src/deep/er", time.Time{}, time.Time{}); len(points) != 0 {
+		t.Errorf("Series below MaxDepth = %d points, want 0", len(points))
+	}
+
+	points, _ = db.Series("/data", feb, time.Time{})
+	if len(points) != 2 {
+		t.Errorf("Series from February = %d points, want 2", len(points))
+	}
+
+	list, err := db.Scans()
+	if err != nil {
+		t.Fatalf("Scans() error = %v", err)
+	}
+	if len(list) != 3 || !list[0].ScanTime.Equal(mar) || list[0].Path != "/data" {
+		t.Errorf("Scans() = %+v, want March first", list[0])
+	}
+
+	if err := db.DeleteScan(ids[1]); err != nil {
+		t.Fatalf("DeleteScan() error = %v", err)
+	}
+	if points, _ := db.Series("/data/logs", time.Time{}, time.Time{}); len(points) != 2 {
+		t.Errorf("Series after DeleteScan = %d points, want 2", len(points))
+	}
+	if err := db.DeleteScan(ids[1]); err == nil {
+		t.Error("deleting a missing scan should fail")
+	}
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Record(scanAt(t, map[string]int{"/data/a": 1}, time.Now()), nil); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer db.Close()
+	if scans, _ := db.Scans(); len(scans) != 1 {
+		t.Errorf("Scans() after reopening = %d, want 1", len(scans))
+	}
+}
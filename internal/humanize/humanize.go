@@ -0,0 +1,27 @@
+// Package humanize formats sizes and ratios for reports, matching the
+// frontend's formatters.
+package humanize
+
+import "fmt"
+
+var sizeUnits = []string{"B", "KB", "MB", "GB", "TB"}
+
+// Bytes formats a byte count with two decimals in 1024-based units, e.g.
+// "1.50 GB".
+func Bytes(n int64) string {
+	size := float64(n)
+	unit := 0
+	for (size >= 1024 || size <= -1024) && unit < len(sizeUnits)-1 {
+		size /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.2f %s", size, sizeUnits[unit])
+}
+
+// Percent formats value as a share of total with one decimal, e.g. "12.5%".
+func Percent(value, total int64) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(value)/float64(total)*100)
+}
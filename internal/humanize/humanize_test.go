@@ -0,0 +1,29 @@
+package humanize
+
+import "testing"
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0.00 B"},
+		{512, "512.00 B"},
+		{1536, "1.50 KB"},
+		{5 << 30, "5.00 GB"},
+		{3 << 50, "3072.00 TB"},
+		{-2048, "-2.00 KB"},
+	}
+	for _, tt := range tests {
+		if got := Bytes(tt.n); got != tt.want {
+			t.Errorf("Bytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+
+	if got := Percent(1, 8); got != "12.5%" {
+		t.Errorf("Percent(1, 8) = %q", got)
+	}
+	if got := Percent(1, 0); got != "0%" {
+		t.Errorf("Percent(1, 0) = %q", got)
+	}
+}
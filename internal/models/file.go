@@ -10,17 +10,43 @@ type FileNode struct {
 	Type         string      `json:"type"` // "file" or "directory"
 	Children     []*FileNode `json:"children,omitempty"`
 	LastModified time.Time   `json:"lastModified"`
+	LastAccessed time.Time   `json:"lastAccessed"`
 	IsHidden     bool        `json:"isHidden"`
 	Permissions  string      `json:"permissions,omitempty"`
+	Owner        string      `json:"owner,omitempty"`
+	Group        string      `json:"group,omitempty"`
+	// IsVirtual marks entries that do not exist on disk, such as files
+	// listed inside an archive. They cannot be deleted or opened.
+	IsVirtual      bool  `json:"isVirtual,omitempty"`
+	CompressedSize int64 `json:"compressedSize,omitempty"`
+	// IsBrokenLink marks a symlink whose target is missing or unreachable
+	IsBrokenLink bool   `json:"isBrokenLink,omitempty"`
+	LinkTarget   string `json:"linkTarget,omitempty"`
+	// AllocatedSize is the disk space in use, summed like Size for
+	// directories. Zero means it is unknown on this platform.
+	AllocatedSize int64 `json:"allocatedSize,omitempty"`
 }
 
 type ScanResult struct {
-	Root             *FileNode `json:"root"`
-	TotalSize        int64     `json:"totalSize"`
-	TotalFiles       int64     `json:"totalFiles"`
-	TotalDirectories int64     `json:"totalDirectories"`
-	ScanTime         time.Time `json:"scanTime"`
-	ScanDurationMs   int64     `json:"scanDuration"`
+	Root             *FileNode      `json:"root"`
+	TotalSize        int64          `json:"totalSize"`
+	TotalFiles       int64          `json:"totalFiles"`
+	TotalDirectories int64          `json:"totalDirectories"`
+	ScanTime         time.Time      `json:"scanTime"`
+	ScanDurationMs   int64          `json:"scanDuration"`
+	FileTypes        *TypeBreakdown `json:"fileTypes,omitempty"`
+}
+
+// TypeStat aggregates files sharing an extension or a category.
+type TypeStat struct {
+	Key   string `json:"key"`
+	Size  int64  `json:"size"`
+	Count int64  `json:"count"`
+}
+
+type TypeBreakdown struct {
+	Extensions []*TypeStat `json:"extensions"`
+	Categories []*TypeStat `json:"categories"`
 }
 
 type ScanProgress struct {
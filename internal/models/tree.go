@@ -0,0 +1,79 @@
+package models
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Node types
+const (
+	TypeFile      = "file"
+	TypeDirectory = "directory"
+)
+
+// Extension returns the lower-cased extension of name without the dot.
+// Dotfiles such as ".bashrc" have no extension.
+func Extension(name string) string {
+	ext := filepath.Ext(name)
+	if ext == name {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+// LastTouched returns the most recent modification time in the subtree, or
+// access time of a file when useAccessTime is set. Directory access times are
+// ignored because listing a directory during a scan updates them, and so are
+// archive entries, whose times are those recorded in the archive.
+func LastTouched(node *FileNode, useAccessTime bool) time.Time {
+	latest := node.LastModified
+	if useAccessTime && node.Type == TypeFile && node.LastAccessed.After(latest) {
+		latest = node.LastAccessed
+	}
+	for _, child := range node.Children {
+		if child.IsVirtual {
+			continue
+		}
+		if t := LastTouched(child, useAccessTime); t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// Filter returns a copy of the tree keeping the files that match and the
+// directories leading to them, with directory sizes recomputed. Only files
+// are tested, so a directory condition such as "dirs > 1GB" keeps nothing
+// while "under x" keeps the files below x. The root is always kept.
+func Filter(root *FileNode, match func(node *FileNode) bool) *FileNode {
+	if root == nil {
+		return nil
+	}
+
+	var visit func(node *FileNode) *FileNode
+	visit = func(node *FileNode) *FileNode {
+		if node.Type == TypeFile {
+			if match(node) {
+				return node
+			}
+			return nil
+		}
+
+		copied := *node
+		copied.Children = []*FileNode{}
+		copied.Size, copied.AllocatedSize = 0, 0
+		for _, child := range node.Children {
+			if kept := visit(child); kept != nil {
+				copied.Children = append(copied.Children, kept)
+				copied.Size += kept.Size
+				copied.AllocatedSize += kept.AllocatedSize
+			}
+		}
+		if len(copied.Children) == 0 && node != root {
+			return nil
+		}
+		return &copied
+	}
+	return visit(root)
+}
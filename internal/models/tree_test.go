@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtension(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"photo.JPG", "jpg"},
+		{"archive.tar.gz", "gz"},
+		{".bashrc", ""},
+		{"README", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Extension(tt.name); got != tt.want {
+				t.Errorf("Extension() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastTouched(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	root := &FileNode{Type: TypeDirectory, LastModified: day(1), LastAccessed: day(20), Children: []*FileNode{
+		{Type: TypeFile, LastModified: day(2), LastAccessed: day(5)},
+		{Type: TypeFile, LastModified: day(3), Children: []*FileNode{
+			{Type: TypeFile, LastModified: day(9), IsVirtual: true},
+		}},
+	}}
+
+	if got := LastTouched(root, true); !got.Equal(day(5)) {
+		t.Errorf("LastTouched(root, true) = %v, want the file access time", got)
+	}
+	if got := LastTouched(root, false); !got.Equal(day(3)) {
+		t.Errorf("LastTouched(root, false) = %v, want the latest modification", got)
+	}
+}
@@ -0,0 +1,387 @@
+// Package ncdu reads and writes the JSON export format of ncdu (`ncdu -o`),
+// so that scans made on servers with ncdu can be viewed here and the other
+// way round.
+//
+// The format is [1, minor, metadata, dir], where a directory is an array
+// whose first element describes the directory itself and whose remaining
+// elements are file objects or nested directory arrays. ncdu stores the
+// size of each directory's own inode rather than a total; totals are
+// recomputed from the files here and left out when writing. Like ncdu, a
+// file with several hard links counts once towards each directory above
+// its links and towards the total, while every link still shows its size.
+package ncdu
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+const (
+	majorVersion = 1
+	minorVersion = 2
+	maxDepth     = 4096
+)
+
+// Metadata is the header ncdu writes before the tree.
+type Metadata struct {
+	Progname  string `json:"progname"`
+	Progver   string `json:"progver"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type entry struct {
+	Name      string `json:"name"`
+	Asize     int64  `json:"asize,omitempty"`
+	Dsize     int64  `json:"dsize,omitempty"`
+	ReadError bool   `json:"read_error,omitempty"`
+	Excluded  string `json:"excluded,omitempty"`
+	Notreg    bool   `json:"notreg,omitempty"`
+	UID       *int64 `json:"uid,omitempty"`
+	GID       *int64 `json:"gid,omitempty"`
+	Mode      *int64 `json:"mode,omitempty"`
+	Mtime     int64  `json:"mtime,omitempty"`
+	// Dev is only written where it differs from the parent directory
+	Dev   *uint64 `json:"dev,omitempty"`
+	Ino   uint64  `json:"ino,omitempty"`
+	Hlnkc bool    `json:"hlnkc,omitempty"`
+	Nlink int64   `json:"nlink,omitempty"`
+}
+
+// inode identifies a hard-linked file across its links.
+type inode struct {
+	dev, ino uint64
+}
+
+// Read imports an ncdu export. The file is decoded token by token, so the
+// tree is the only copy held in memory. Entries ncdu excluded from its scan
+// are skipped.
+func Read(r io.Reader) (*models.ScanResult, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, fmt.Errorf("not an ncdu export: %w", err)
+	}
+	var major, minor json.Number
+	if err := dec.Decode(&major); err != nil {
+		return nil, fmt.Errorf("not an ncdu export: %w", err)
+	}
+	if major.String() != strconv.Itoa(majorVersion) {
+		return nil, fmt.Errorf("unsupported ncdu export version %s", major)
+	}
+	if err := dec.Decode(&minor); err != nil {
+		return nil, fmt.Errorf("invalid ncdu export: %w", err)
+	}
+	var meta Metadata
+	if err := dec.Decode(&meta); err != nil {
+		return nil, fmt.Errorf("invalid ncdu metadata: %w", err)
+	}
+
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, fmt.Errorf("invalid ncdu export: root is not a directory: %w", err)
+	}
+	root, _, err := readDir(dec, "", 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ncdu export: %w", err)
+	}
+	if root == nil {
+		return nil, fmt.Errorf("invalid ncdu export: root directory is excluded")
+	}
+	if err := expectDelim(dec, ']'); err != nil {
+		return nil, fmt.Errorf("invalid ncdu export: %w", err)
+	}
+
+	scanTime := time.Now()
+	if meta.Timestamp > 0 {
+		scanTime = time.Unix(meta.Timestamp, 0)
+	}
+	result := scanner.NewResult(root, scanTime)
+	// The sum over the files counts hard links once per link
+	result.TotalSize = root.Size
+	return result, nil
+}
+
+// readDir reads a directory array whose opening bracket has been consumed.
+// It returns nil for an excluded directory, and the hard-linked files below
+// it by inode so that the parent counts each of them once.
+func readDir(dec *json.Decoder, parentPath string, dev uint64, depth int) (*models.FileNode, map[inode]*models.FileNode, error) {
+	if depth > maxDepth {
+		return nil, nil, errors.New("directories nested too deeply")
+	}
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, nil, err
+	}
+	info, err := readEntry(dec)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.Dev != nil {
+		dev = *info.Dev
+	}
+	dir := newNode(info, parentPath, scanner.FileTypeDirectory)
+	dir.Children = []*models.FileNode{}
+	links := make(map[inode]*models.FileNode)
+
+	// add counts a hard-linked file towards dir unless another link to it
+	// already was
+	add := func(key inode, file *models.FileNode) {
+		if _, ok := links[key]; ok {
+			dir.Size -= file.Size
+			dir.AllocatedSize -= file.AllocatedSize
+			return
+		}
+		links[key] = file
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var child *models.FileNode
+		switch tok {
+		case json.Delim('['):
+			var childLinks map[inode]*models.FileNode
+			if child, childLinks, err = readDir(dec, dir.Path, dev, depth+1); err == nil && child != nil {
+				for key, file := range childLinks {
+					add(key, file)
+				}
+			}
+		case json.Delim('{'):
+			var e *entry
+			if e, err = readEntry(dec); err == nil && e.Excluded == "" {
+				child = newNode(e, dir.Path, scanner.FileTypeFile)
+				child.Size, child.AllocatedSize = e.Asize, e.Dsize
+				if e.Ino != 0 && (e.Hlnkc || e.Nlink > 1) {
+					key := inode{dev, e.Ino}
+					if e.Dev != nil {
+						key.dev = *e.Dev
+					}
+					add(key, child)
+				}
+			}
+		default:
+			err = fmt.Errorf("unexpected %v in directory %s", tok, dir.Path)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if child != nil {
+			dir.Children = append(dir.Children, child)
+			dir.Size += child.Size
+			dir.AllocatedSize += child.AllocatedSize
+		}
+	}
+	if err := expectDelim(dec, ']'); err != nil {
+		return nil, nil, err
+	}
+	if info.Excluded != "" {
+		return nil, nil, nil
+	}
+	return dir, links, nil
+}
+
+// readEntry reads the fields of an object whose opening brace has been
+// consumed. Unknown fields are skipped.
+func readEntry(dec *json.Decoder) (*entry, error) {
+	e := &entry{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var target any
+		switch key {
+		case "name":
+			target = &e.Name
+		case "asize":
+			target = &e.Asize
+		case "dsize":
+			target = &e.Dsize
+		case "read_error":
+			target = &e.ReadError
+		case "excluded":
+			target = &e.Excluded
+		case "notreg":
+			target = &e.Notreg
+		case "uid":
+			target = &e.UID
+		case "gid":
+			target = &e.GID
+		case "mode":
+			target = &e.Mode
+		case "mtime":
+			target = &e.Mtime
+		case "dev":
+			target = &e.Dev
+		case "ino":
+			target = &e.Ino
+		case "hlnkc":
+			target = &e.Hlnkc
+		case "nlink":
+			target = &e.Nlink
+		default:
+			target = &json.RawMessage{}
+		}
+		if err := dec.Decode(target); err != nil {
+			return nil, fmt.Errorf("field %q: %w", key, err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	if e.Name == "" {
+		return nil, errors.New("entry without a name")
+	}
+	return e, nil
+}
+
+func newNode(e *entry, parentPath, fileType string) *models.FileNode {
+	path := e.Name
+	name := e.Name
+	if parentPath != "" {
+		path = filepath.Join(parentPath, e.Name)
+	} else {
+		// The root entry carries the scanned path
+		name = filepath.Base(e.Name)
+	}
+
+	node := &models.FileNode{
+		ID:       scanner.GenerateID(path),
+		Name:     name,
+		Path:     path,
+		Type:     fileType,
+		IsHidden: strings.HasPrefix(name, "."),
+	}
+	if e.Mtime > 0 {
+		node.LastModified = time.Unix(e.Mtime, 0)
+	}
+	if e.Mode != nil {
+		node.Permissions = unixMode(*e.Mode).String()
+	}
+	// ncdu only knows numeric IDs, which is also what the scanner shows for
+	// accounts it cannot resolve
+	if e.UID != nil {
+		node.Owner = strconv.FormatInt(*e.UID, 10)
+	}
+	if e.GID != nil {
+		node.Group = strconv.FormatInt(*e.GID, 10)
+	}
+	return node
+}
+
+// unixMode converts a raw st_mode value to an fs.FileMode.
+func unixMode(mode int64) fs.FileMode {
+	m := fs.FileMode(mode & 0o777)
+	switch mode & 0o170000 {
+	case 0o040000:
+		m |= fs.ModeDir
+	case 0o120000:
+		m |= fs.ModeSymlink
+	case 0o010000:
+		m |= fs.ModeNamedPipe
+	case 0o140000:
+		m |= fs.ModeSocket
+	case 0o020000:
+		m |= fs.ModeDevice | fs.ModeCharDevice
+	case 0o060000:
+		m |= fs.ModeDevice
+	}
+	if mode&0o4000 != 0 {
+		m |= fs.ModeSetuid
+	}
+	if mode&0o2000 != 0 {
+		m |= fs.ModeSetgid
+	}
+	if mode&0o1000 != 0 {
+		m |= fs.ModeSticky
+	}
+	return m
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("expected %q, found %v", want, tok)
+	}
+	return nil
+}
+
+// Write exports result in ncdu's format, naming progname as the program that
+// wrote it. Files without a known allocated size report their apparent size
+// as disk usage, and entries inside archives are left out.
+func Write(w io.Writer, result *models.ScanResult, progname, progver string) error {
+	if result == nil || result.Root == nil {
+		return fmt.Errorf("no scan result to export")
+	}
+
+	bw := bufio.NewWriter(w)
+	meta, err := json.Marshal(&Metadata{Progname: progname, Progver: progver, Timestamp: result.ScanTime.Unix()})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(bw, "[%d,%d,%s,\n", majorVersion, minorVersion, meta)
+	if err := writeNode(bw, result.Root, true); err != nil {
+		return err
+	}
+	bw.WriteString("]\n")
+	return bw.Flush()
+}
+
+func writeNode(w *bufio.Writer, node *models.FileNode, isRoot bool) error {
+	e := &entry{Name: node.Name}
+	if isRoot {
+		e.Name = node.Path
+	}
+	if !node.LastModified.IsZero() {
+		e.Mtime = node.LastModified.Unix()
+	}
+
+	if node.Type != scanner.FileTypeDirectory {
+		e.Asize = node.Size
+		e.Dsize = node.AllocatedSize
+		if e.Dsize == 0 {
+			e.Dsize = node.Size
+		}
+		e.Notreg = node.IsBrokenLink
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	w.WriteByte('[')
+	w.Write(data)
+	for _, child := range node.Children {
+		if child.IsVirtual {
+			continue
+		}
+		w.WriteString(",\n")
+		if err := writeNode(w, child, false); err != nil {
+			return err
+		}
+	}
+	_, err = w.WriteString("]")
+	return err
+}
@@ -0,0 +1,168 @@
+package ncdu
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+// Output of `ncdu -e -o -` trimmed to a few entries
+const sample = `[1,2,{"progname":"ncdu","progver":"1.19","timestamp":1700000000},
+[{"name":"/srv/data","asize":4096,"dsize":4096,"dev":2049,"ino":131073,"uid":0,"gid":0,"mode":16877,"mtime":1699990000},
+{"name":"notes.txt","asize":1200,"dsize":4096,"ino":131074,"uid":1000,"gid":1000,"mode":33188,"mtime":1699000000},
+{"name":".env","asize":30,"dsize":4096,"ino":131075},
+{"name":"current","asize":9,"dsize":0,"ino":131076,"notreg":true},
+[{"name":"logs","asize":4096,"dsize":4096,"ino":131077},
+{"name":"app.log","asize":50000,"dsize":53248,"ino":131078,"hlnkc":true,"nlink":2},
+{"name":"big.iso","excluded":"pattern"}],
+[{"name":"proc","excluded":"kernfs"}],
+[{"name":"empty","asize":4096,"dsize":4096,"read_error":true}]
+]]`
+
+func findChild(node *models.FileNode, name string) *models.FileNode {
+	for _, child := range node.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func TestRead(t *testing.T) {
+	result, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	root := result.Root
+	if root.Path != "/srv/data" || root.Name != "data" || root.Type != scanner.FileTypeDirectory {
+		t.Errorf("root = %s %s %s", root.Path, root.Name, root.Type)
+	}
+	if result.TotalSize != 1200+30+9+50000 || result.TotalFiles != 4 || result.TotalDirectories != 3 {
+		t.Errorf("totals = %d bytes, %d files, %d dirs", result.TotalSize, result.TotalFiles, result.TotalDirectories)
+	}
+	if !result.ScanTime.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("ScanTime = %v", result.ScanTime)
+	}
+	if findChild(root, "proc") != nil {
+		t.Error("excluded directory should be skipped")
+	}
+
+	notes := findChild(root, "notes.txt")
+	if notes == nil || notes.Path != "/srv/data/notes.txt" || notes.AllocatedSize != 4096 || notes.Owner != "1000" || notes.Permissions != "-rw-r--r--" {
+		t.Errorf("notes.txt = %+v", notes)
+	}
+	if !notes.LastModified.Equal(time.Unix(1699000000, 0)) || notes.ID != scanner.GenerateID("/srv/data/notes.txt") {
+		t.Errorf("notes.txt time or id = %v %s", notes.LastModified, notes.ID)
+	}
+	if env := findChild(root, ".env"); env == nil || !env.IsHidden {
+		t.Errorf(".env = %+v, want hidden", env)
+	}
+	if root.Permissions != "drwxr-xr-x" {
+		t.Errorf("root permissions = %s", root.Permissions)
+	}
+
+	logs := findChild(root, "logs")
+	if logs == nil || logs.Size != 50000 || logs.AllocatedSize != 53248 || len(logs.Children) != 1 {
+		t.Errorf("logs = %+v", logs)
+	}
+}
+
+func TestRead_HardLinks(t *testing.T) {
+	// One 100-byte inode linked from a/ and b/, a second link inside b/, and
+	// a file on another device that reuses the inode number
+	input := `[1,2,{"progname":"ncdu","progver":"1.19","timestamp":1700000000},
+[{"name":"/srv","dev":1,"ino":2},
+[{"name":"a","ino":3},
+{"name":"one","asize":100,"dsize":4096,"ino":10,"hlnkc":true,"nlink":3}],
+[{"name":"b","ino":4},
+{"name":"two","asize":100,"dsize":4096,"ino":10,"hlnkc":true,"nlink":3},
+{"name":"three","asize":100,"dsize":4096,"ino":10,"hlnkc":true,"nlink":3}],
+[{"name":"mnt","dev":2,"ino":2},
+{"name":"other","asize":7,"dsize":4096,"ino":10,"hlnkc":true,"nlink":2}]
+]]`
+
+	result, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if result.TotalSize != 107 || result.Root.Size != 107 || result.Root.AllocatedSize != 8192 {
+		t.Errorf("total = %d, root = %d (%d allocated), want the linked file counted once", result.TotalSize, result.Root.Size, result.Root.AllocatedSize)
+	}
+	if a := findChild(result.Root, "a"); a == nil || a.Size != 100 {
+		t.Errorf("a = %+v", a)
+	}
+	b := findChild(result.Root, "b")
+	if b == nil || b.Size != 100 || len(b.Children) != 2 || b.Children[1].Size != 100 {
+		t.Errorf("b = %+v, want both links listed and counted once", b)
+	}
+	if result.TotalFiles != 4 {
+		t.Errorf("TotalFiles = %d, want every link", result.TotalFiles)
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not json", "hello"},
+		{"object", `{"name":"x"}`},
+		{"newer major version", `[2,0,{},[{"name":"/"}]]`},
+		{"root is a file", `[1,2,{},{"name":"/x"}]`},
+		{"truncated", sample[:len(sample)/2]},
+		{"entry without name", `[1,2,{},[{"name":"/"},{"asize":1}]]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read(strings.NewReader(tt.input)); err == nil {
+				t.Error("Read() should fail")
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/a.txt", make([]byte, 100))
+	_ = m.WriteFile("/data/sub/b\"q.bin", make([]byte, 7000))
+	_ = m.SetAllocated("/data/sub/b\"q.bin", 8192)
+	_ = m.Symlink("/missing", "/data/dangling")
+	scanned, err := scanner.NewScannerWithFS(m, scanner.DefaultScanOptions()).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, scanned, "vizdisk", "1.2.0"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		t.Fatalf("output is not valid JSON:\n%s", buf.String())
+	}
+	if !strings.HasPrefix(buf.String(), `[1,2,{"progname":"vizdisk","progver":"1.2.0"`) {
+		t.Errorf("header = %.60s", buf.String())
+	}
+
+	imported, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("reading the export back: %v", err)
+	}
+	if imported.TotalSize != scanned.TotalSize || imported.TotalFiles != scanned.TotalFiles || imported.Root.Path != "/data" {
+		t.Errorf("round trip = %d bytes in %d files, want %d in %d", imported.TotalSize, imported.TotalFiles, scanned.TotalSize, scanned.TotalFiles)
+	}
+	sub := findChild(imported.Root, "sub")
+	if sub == nil || sub.AllocatedSize != 8192 {
+		t.Errorf("sub = %+v", sub)
+	}
+	// Unknown allocation falls back to the apparent size
+	if a := findChild(imported.Root, "a.txt"); a == nil || a.AllocatedSize != 100 {
+		t.Errorf("a.txt = %+v", a)
+	}
+}
@@ -0,0 +1,399 @@
+package oci
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Analyzer reconstructs the merged filesystem of a container image stored
+// locally as an OCI image layout directory or a `docker save` tarball.
+type Analyzer struct {
+	fs vfs.FS
+}
+
+// Image is the result of analyzing a container image. Result holds one
+// virtual directory per layer containing the files that layer contributes to
+// the merged filesystem.
+type Image struct {
+	Name   string             `json:"name"`
+	Layers []*Layer           `json:"layers"`
+	Result *models.ScanResult `json:"result"`
+}
+
+// Layer summarizes one image layer. ShadowedSize counts bytes the layer
+// ships that are overwritten or whited out by a later layer.
+type Layer struct {
+	Index            int    `json:"index"`
+	Digest           string `json:"digest"`
+	Size             int64  `json:"size"`
+	UncompressedSize int64  `json:"uncompressedSize"`
+	VisibleSize      int64  `json:"visibleSize"`
+	ShadowedSize     int64  `json:"shadowedSize"`
+	Files            int64  `json:"files"`
+}
+
+func NewAnalyzer(fsys vfs.FS) *Analyzer {
+	return &Analyzer{fs: fsys}
+}
+
+func (a *Analyzer) Analyze(imagePath string) (*Image, error) {
+	startTime := time.Now()
+	imagePath = filepath.Clean(imagePath)
+
+	info, err := a.fs.Stat(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access image: %w", err)
+	}
+
+	var src source
+	if info.IsDir() {
+		src = &dirSource{fs: a.fs, root: imagePath}
+	} else {
+		src = &tarSource{fs: a.fs, path: imagePath}
+	}
+
+	name, refs, err := resolveLayers(src)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = filepath.Base(imagePath)
+	}
+
+	merged := newMergedFS()
+	layers := make([]*Layer, len(refs))
+	for i, ref := range refs {
+		layers[i] = &Layer{Index: i, Digest: ref.digest, Size: ref.size}
+		if err := a.applyLayer(src, ref, layers[i], merged); err != nil {
+			return nil, fmt.Errorf("failed to read layer %d (%s): %w", i, ref.digest, err)
+		}
+	}
+
+	root := &models.FileNode{
+		ID:           scanner.GenerateID(imagePath),
+		Name:         name,
+		Path:         imagePath,
+		Type:         scanner.FileTypeDirectory,
+		LastModified: info.ModTime(),
+		IsVirtual:    true,
+	}
+	root.Children = buildLayerTrees(imagePath, merged, layers)
+	for _, child := range root.Children {
+		root.Size += child.Size
+	}
+
+	result := scanner.NewResult(root, startTime)
+	result.ScanDurationMs = time.Since(startTime).Milliseconds()
+
+	return &Image{Name: name, Layers: layers, Result: result}, nil
+}
+
+func (a *Analyzer) applyLayer(src source, ref layerRef, layer *Layer, merged *mergedFS) error {
+	r, err := src.open(ref.name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	counter := &countingReader{r: r}
+	stream, err := decompress(counter)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	tr := tar.NewReader(stream)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := cleanPath(header.Name)
+		if name == "" || name == "." {
+			continue
+		}
+		dir, base := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+
+		switch {
+		case base == whiteoutOpaque:
+			merged.opaque(dir, layer.Index)
+		case strings.HasPrefix(base, whiteoutPrefix):
+			merged.remove(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+		case header.Typeflag == tar.TypeDir:
+			merged.mkdir(name, layer.Index, header.ModTime)
+		default:
+			var size int64
+			if header.Typeflag == tar.TypeReg {
+				size = header.Size
+				layer.UncompressedSize += size
+				layer.Files++
+			}
+			merged.put(name, &mergedNode{layer: layer.Index, size: size, modTime: header.ModTime})
+		}
+	}
+
+	if layer.Size == 0 {
+		// Drain the remainder so the compressed size is exact for legacy
+		// docker-save layers, whose manifest carries no sizes
+		_, _ = io.Copy(io.Discard, counter)
+		layer.Size = counter.n
+	}
+	return nil
+}
+
+// decompress sniffs the layer blob and unwraps gzip compression.
+func decompress(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		return nil, fmt.Errorf("zstd compressed layers are not supported")
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// buildLayerTrees groups the files that survive in the merged filesystem by
+// the layer that provided them and updates the layer statistics.
+func buildLayerTrees(imagePath string, merged *mergedFS, layers []*Layer) []*models.FileNode {
+	layerRoots := make([]*models.FileNode, len(layers))
+	for i, layer := range layers {
+		nodePath := filepath.Join(imagePath, fmt.Sprintf("layer-%02d", i))
+		layerRoots[i] = &models.FileNode{
+			ID:        scanner.GenerateID(nodePath),
+			Name:      layerName(i, layer.Digest),
+			Path:      nodePath,
+			Type:      scanner.FileTypeDirectory,
+			IsVirtual: true,
+		}
+	}
+
+	merged.walk(func(name string, node *mergedNode) {
+		if node.isDir() || node.layer >= len(layerRoots) {
+			return
+		}
+		layers[node.layer].VisibleSize += node.size
+
+		layerRoot := layerRoots[node.layer]
+		parent := layerRoot
+		parts := strings.Split(name, "/")
+		for i, part := range parts[:len(parts)-1] {
+			parent = childDir(parent, part, path.Join(parts[:i+1]...), layerRoot.Path)
+		}
+		nodePath := filepath.Join(layerRoot.Path, filepath.FromSlash(name))
+		parent.Children = append(parent.Children, &models.FileNode{
+			ID:           scanner.GenerateID(nodePath),
+			Name:         parts[len(parts)-1],
+			Path:         nodePath,
+			Type:         scanner.FileTypeFile,
+			Size:         node.size,
+			LastModified: node.modTime,
+			IsHidden:     strings.HasPrefix(parts[len(parts)-1], "."),
+			IsVirtual:    true,
+		})
+	})
+
+	for i, layer := range layers {
+		layer.ShadowedSize = layer.UncompressedSize - layer.VisibleSize
+		sumSizes(layerRoots[i])
+	}
+	return layerRoots
+}
+
+func childDir(parent *models.FileNode, name, relPath, layerPath string) *models.FileNode {
+	for _, child := range parent.Children {
+		if child.Name == name && child.Type == scanner.FileTypeDirectory {
+			return child
+		}
+	}
+	nodePath := filepath.Join(layerPath, filepath.FromSlash(relPath))
+	dir := &models.FileNode{
+		ID:        scanner.GenerateID(nodePath),
+		Name:      name,
+		Path:      nodePath,
+		Type:      scanner.FileTypeDirectory,
+		IsHidden:  strings.HasPrefix(name, "."),
+		IsVirtual: true,
+	}
+	parent.Children = append(parent.Children, dir)
+	return dir
+}
+
+func sumSizes(node *models.FileNode) int64 {
+	if node.Type == scanner.FileTypeFile {
+		return node.Size
+	}
+	node.Size = 0
+	for _, child := range node.Children {
+		node.Size += sumSizes(child)
+	}
+	return node.Size
+}
+
+func layerName(index int, digest string) string {
+	short := strings.TrimPrefix(digest, "sha256:")
+	if strings.HasSuffix(short, "/layer.tar") {
+		short = strings.TrimSuffix(short, "/layer.tar")
+	}
+	short = path.Base(short)
+	if len(short) > 12 {
+		short = short[:12]
+	}
+	return fmt.Sprintf("layer %d (%s)", index, short)
+}
+
+// mergedFS is the union of the layers applied so far.
+type mergedFS struct {
+	root *mergedNode
+}
+
+type mergedNode struct {
+	layer    int
+	size     int64
+	modTime  time.Time
+	children map[string]*mergedNode
+}
+
+func (n *mergedNode) isDir() bool {
+	return n.children != nil
+}
+
+func newMergedFS() *mergedFS {
+	return &mergedFS{root: &mergedNode{children: map[string]*mergedNode{}}}
+}
+
+// mkdir creates a directory, replacing a file of the same name from a lower
+// layer.
+func (m *mergedFS) mkdir(name string, layer int, modTime time.Time) *mergedNode {
+	node := m.root
+	for _, part := range strings.Split(name, "/") {
+		child, ok := node.children[part]
+		if !ok || !child.isDir() {
+			child = &mergedNode{layer: layer, modTime: modTime, children: map[string]*mergedNode{}}
+			node.children[part] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// put adds or replaces a non-directory entry. Replacing a directory drops
+// everything below it.
+func (m *mergedFS) put(name string, entry *mergedNode) {
+	dir, base := path.Split(name)
+	parent := m.root
+	if dir != "" {
+		parent = m.mkdir(strings.TrimSuffix(dir, "/"), entry.layer, entry.modTime)
+	}
+	parent.children[base] = entry
+}
+
+// remove applies a whiteout for a single path.
+func (m *mergedFS) remove(name string) {
+	dir, base := path.Split(name)
+	parent := m.lookup(strings.TrimSuffix(dir, "/"))
+	if parent != nil && parent.isDir() {
+		delete(parent.children, base)
+	}
+}
+
+// opaque applies an opaque whiteout: everything lower layers put inside dir
+// disappears, while entries from the current layer are kept.
+func (m *mergedFS) opaque(dir string, layer int) {
+	node := m.root
+	if dir != "" {
+		node = m.mkdir(dir, layer, time.Time{})
+	}
+	pruneLower(node, layer)
+}
+
+func pruneLower(node *mergedNode, layer int) {
+	for name, child := range node.children {
+		if child.isDir() {
+			pruneLower(child, layer)
+			if child.layer < layer && len(child.children) == 0 {
+				delete(node.children, name)
+			}
+		} else if child.layer < layer {
+			delete(node.children, name)
+		}
+	}
+}
+
+func (m *mergedFS) lookup(name string) *mergedNode {
+	node := m.root
+	if name == "" {
+		return node
+	}
+	for _, part := range strings.Split(name, "/") {
+		child, ok := node.children[part]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+	return node
+}
+
+// walk visits every entry in lexical path order.
+func (m *mergedFS) walk(fn func(name string, node *mergedNode)) {
+	var visit func(prefix string, node *mergedNode)
+	visit = func(prefix string, node *mergedNode) {
+		names := make([]string, 0, len(node.children))
+		for name := range node.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := node.children[name]
+			childPath := path.Join(prefix, name)
+			fn(childPath, child)
+			if child.isDir() {
+				visit(childPath, child)
+			}
+		}
+	}
+	visit("", m.root)
+}
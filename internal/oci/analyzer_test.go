@@ -0,0 +1,203 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/vfs"
+)
+
+type tarEntry struct {
+	name    string
+	content string
+	dir     bool
+}
+
+func buildTar(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}
+		if entry.dir {
+			header = &tar.Header{Name: entry.name + "/", Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(entry.content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write(data)
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+func digestOf(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+func testLayers(t *testing.T) [][]byte {
+	return [][]byte{
+		buildTar(t, []tarEntry{
+			{name: "etc", dir: true},
+			{name: "etc/config", content: "0123456789"},
+			{name: "usr/bin/tool", content: "tooltooltool"},
+			{name: "var/cache/a", content: "aaaa"},
+			{name: "var/cache/b", content: "bb"},
+		}),
+		buildTar(t, []tarEntry{
+			{name: "etc/config", content: "01234"},
+			{name: "usr/bin/.wh.tool"},
+			{name: "var/cache/.wh..wh..opq"},
+			{name: "var/cache/c", content: "c"},
+		}),
+	}
+}
+
+func findNode(node *models.FileNode, names ...string) *models.FileNode {
+	for _, name := range names {
+		var next *models.FileNode
+		for _, child := range node.Children {
+			if child.Name == name {
+				next = child
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+func checkMergedImage(t *testing.T, image *Image) {
+	t.Helper()
+
+	if len(image.Layers) != 2 {
+		t.Fatalf("Layers = %d, want 2", len(image.Layers))
+	}
+	base, top := image.Layers[0], image.Layers[1]
+	if base.UncompressedSize != 28 || base.VisibleSize != 0 || base.ShadowedSize != 28 {
+		t.Errorf("base layer = %+v, want 28 bytes all shadowed", base)
+	}
+	if top.UncompressedSize != 6 || top.VisibleSize != 6 {
+		t.Errorf("top layer = %+v, want 6 visible bytes", top)
+	}
+	if image.Result.TotalSize != 6 || image.Result.TotalFiles != 2 {
+		t.Errorf("Result totals = %d bytes, %d files, want 6, 2", image.Result.TotalSize, image.Result.TotalFiles)
+	}
+
+	topNode := image.Result.Root.Children[1]
+	if config := findNode(topNode, "etc", "config"); config == nil || config.Size != 5 || !config.IsVirtual {
+		t.Errorf("etc/config = %+v, want 5 byte virtual file", config)
+	}
+	if c := findNode(topNode, "var", "cache", "c"); c == nil {
+		t.Error("var/cache/c missing from top layer")
+	}
+	if tool := findNode(image.Result.Root.Children[0], "usr", "bin", "tool"); tool != nil {
+		t.Error("whited out file still visible")
+	}
+}
+
+func TestAnalyzer_OCILayout(t *testing.T) {
+	m := vfs.NewMemFS()
+
+	var layerDescs []map[string]any
+	for _, layer := range testLayers(t) {
+		blob := gzipBytes(layer)
+		digest := digestOf(blob)
+		_ = m.WriteFile("/image/blobs/sha256/"+digest[len("sha256:"):], blob)
+		layerDescs = append(layerDescs, map[string]any{
+			"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip",
+			"digest":    digest,
+			"size":      len(blob),
+		})
+	}
+
+	manifest, _ := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"layers":        layerDescs,
+	})
+	manifestDigest := digestOf(manifest)
+	_ = m.WriteFile("/image/blobs/sha256/"+manifestDigest[len("sha256:"):], manifest)
+
+	index, _ := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"manifests": []map[string]any{{
+			"mediaType":   "application/vnd.oci.image.manifest.v1+json",
+			"digest":      manifestDigest,
+			"size":        len(manifest),
+			"annotations": map[string]string{annotationRefName: "example:latest"},
+		}},
+	})
+	_ = m.WriteFile("/image/index.json", index)
+	_ = m.WriteFile("/image/oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`))
+
+	image, err := NewAnalyzer(m).Analyze("/image")
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if image.Name != "example:latest" {
+		t.Errorf("Name = %q, want example:latest", image.Name)
+	}
+	checkMergedImage(t, image)
+}
+
+func TestAnalyzer_DockerSave(t *testing.T) {
+	layers := testLayers(t)
+	manifest, _ := json.Marshal([]map[string]any{{
+		"Config":   "config.json",
+		"RepoTags": []string{"app:1.0"},
+		"Layers":   []string{"aaa/layer.tar", "bbb/layer.tar"},
+	}})
+
+	archive := buildTar(t, []tarEntry{
+		{name: "aaa/layer.tar", content: string(layers[0])},
+		{name: "bbb/layer.tar", content: string(layers[1])},
+		{name: "config.json", content: "{}"},
+		{name: "manifest.json", content: string(manifest)},
+	})
+
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/images/app.tar", archive)
+
+	image, err := NewAnalyzer(m).Analyze("/images/app.tar")
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if image.Name != "app:1.0" {
+		t.Errorf("Name = %q, want app:1.0", image.Name)
+	}
+	if image.Layers[0].Size != int64(len(layers[0])) {
+		t.Errorf("layer size = %d, want %d", image.Layers[0].Size, len(layers[0]))
+	}
+	checkMergedImage(t, image)
+}
+
+func TestAnalyzer_NotAnImage(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/dir/readme", []byte("x"))
+
+	if _, err := NewAnalyzer(m).Analyze("/dir"); err == nil {
+		t.Error("Analyze() should error without manifest")
+	}
+	if _, err := NewAnalyzer(m).Analyze("/missing"); err == nil {
+		t.Error("Analyze() should error on missing path")
+	}
+}
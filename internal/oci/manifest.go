@@ -0,0 +1,174 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+const (
+	mediaTypeOCIIndex         = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerList       = "application/vnd.docker.distribution.manifest.list.v2+json"
+	annotationRefName         = "org.opencontainers.image.ref.name"
+	maxManifestSize     int64 = 16 << 20
+)
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform,omitempty"`
+}
+
+type imageIndex struct {
+	MediaType string       `json:"mediaType"`
+	Manifests []descriptor `json:"manifests"`
+}
+
+type imageManifest struct {
+	MediaType string       `json:"mediaType"`
+	Layers    []descriptor `json:"layers"`
+}
+
+type dockerManifest struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+// layerRef points at a layer blob inside the image source.
+type layerRef struct {
+	name   string
+	digest string
+	size   int64
+}
+
+func readJSON(src source, name string, v any) error {
+	r, err := src.open(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := json.NewDecoder(io.LimitReader(r, maxManifestSize)).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// resolveLayers finds the layer list of the image. A docker-save
+// manifest.json takes precedence, since newer docker versions write it next
+// to an OCI index.json.
+func resolveLayers(src source) (string, []layerRef, error) {
+	if src.exists("manifest.json") {
+		return resolveDockerLayers(src)
+	}
+	if src.exists("index.json") {
+		return resolveOCILayers(src)
+	}
+	return "", nil, fmt.Errorf("neither manifest.json nor index.json found")
+}
+
+func resolveDockerLayers(src source) (string, []layerRef, error) {
+	var manifests []dockerManifest
+	if err := readJSON(src, "manifest.json", &manifests); err != nil {
+		return "", nil, err
+	}
+	if len(manifests) == 0 {
+		return "", nil, fmt.Errorf("manifest.json lists no images")
+	}
+
+	manifest := manifests[0]
+	var name string
+	if len(manifest.RepoTags) > 0 {
+		name = manifest.RepoTags[0]
+	}
+
+	layers := make([]layerRef, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		name := cleanPath(layer)
+		digest := name
+		if algorithm, hex, ok := strings.Cut(strings.TrimPrefix(name, "blobs/"), "/"); ok && strings.HasPrefix(name, "blobs/") {
+			digest = algorithm + ":" + hex
+		}
+		layers = append(layers, layerRef{name: name, digest: digest})
+	}
+	return name, layers, nil
+}
+
+func resolveOCILayers(src source) (string, []layerRef, error) {
+	var index imageIndex
+	if err := readJSON(src, "index.json", &index); err != nil {
+		return "", nil, err
+	}
+
+	desc, err := selectManifest(index.Manifests)
+	if err != nil {
+		return "", nil, err
+	}
+	name := desc.Annotations[annotationRefName]
+
+	// Follow nested indexes (multi-platform images) down to one manifest
+	for depth := 0; isIndex(desc.MediaType); depth++ {
+		if depth > 4 {
+			return "", nil, fmt.Errorf("image index nesting too deep")
+		}
+		blob, err := blobPath(desc.Digest)
+		if err != nil {
+			return "", nil, err
+		}
+		var nested imageIndex
+		if err := readJSON(src, blob, &nested); err != nil {
+			return "", nil, err
+		}
+		if desc, err = selectManifest(nested.Manifests); err != nil {
+			return "", nil, err
+		}
+	}
+
+	blob, err := blobPath(desc.Digest)
+	if err != nil {
+		return "", nil, err
+	}
+	var manifest imageManifest
+	if err := readJSON(src, blob, &manifest); err != nil {
+		return "", nil, err
+	}
+	if isIndex(manifest.MediaType) {
+		return "", nil, fmt.Errorf("manifest %s is an unsupported nested index", desc.Digest)
+	}
+
+	layers := make([]layerRef, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		name, err := blobPath(layer.Digest)
+		if err != nil {
+			return "", nil, err
+		}
+		layers = append(layers, layerRef{name: name, digest: layer.Digest, size: layer.Size})
+	}
+	return name, layers, nil
+}
+
+// selectManifest picks the manifest for the host architecture when the
+// index carries platform information, and the first one otherwise.
+func selectManifest(manifests []descriptor) (descriptor, error) {
+	if len(manifests) == 0 {
+		return descriptor{}, fmt.Errorf("image index lists no manifests")
+	}
+	for _, desc := range manifests {
+		if desc.Platform != nil && desc.Platform.Architecture == runtime.GOARCH {
+			return desc, nil
+		}
+	}
+	return manifests[0], nil
+}
+
+func isIndex(mediaType string) bool {
+	return mediaType == mediaTypeOCIIndex || mediaType == mediaTypeDockerList
+}
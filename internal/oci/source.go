@@ -0,0 +1,99 @@
+package oci
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"vizdisk/internal/vfs"
+)
+
+// source gives access to the files of an image, either unpacked as an OCI
+// layout directory or packed in a docker-save/OCI tarball.
+type source interface {
+	open(name string) (io.ReadCloser, error)
+	exists(name string) bool
+}
+
+type dirSource struct {
+	fs   vfs.FS
+	root string
+}
+
+func (d *dirSource) open(name string) (io.ReadCloser, error) {
+	return d.fs.Open(filepath.Join(d.root, filepath.FromSlash(name)))
+}
+
+func (d *dirSource) exists(name string) bool {
+	_, err := d.fs.Stat(filepath.Join(d.root, filepath.FromSlash(name)))
+	return err == nil
+}
+
+// tarSource reads members of a tarball by rescanning it from the start for
+// each lookup. Tar readers skip member contents by seeking, so this only
+// costs a pass over the headers.
+type tarSource struct {
+	fs   vfs.FS
+	path string
+}
+
+type tarMember struct {
+	io.Reader
+	file vfs.File
+}
+
+func (m *tarMember) Close() error {
+	return m.file.Close()
+}
+
+func (t *tarSource) open(name string) (io.ReadCloser, error) {
+	f, err := t.fs.Open(t.path)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			f.Close()
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to read %s: %w", t.path, err)
+		}
+		if cleanPath(header.Name) == cleanPath(name) {
+			return &tarMember{Reader: tr, file: f}, nil
+		}
+	}
+}
+
+func (t *tarSource) exists(name string) bool {
+	r, err := t.open(name)
+	if err != nil {
+		return false
+	}
+	r.Close()
+	return true
+}
+
+// cleanPath normalizes a tar entry name to a relative slash path that cannot
+// escape the image root.
+func cleanPath(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	return strings.TrimPrefix(name, "/")
+}
+
+func blobPath(digest string) (string, error) {
+	algorithm, hex, ok := strings.Cut(digest, ":")
+	if !ok || algorithm == "" || hex == "" || strings.ContainsAny(digest, "/\\") {
+		return "", fmt.Errorf("invalid digest: %q", digest)
+	}
+	return path.Join("blobs", algorithm, hex), nil
+}
@@ -0,0 +1,486 @@
+// Package query implements a small expression language for selecting nodes
+// of a scanned tree, for example
+//
+//	files > 100MB, not touched in 1 year, under ~/Projects, extension in (mp4, mov)
+//
+// Terms are joined with "," or "and", alternatives with "or", and negated
+// with "not"; parentheses group. The supported terms are:
+//
+//	files, dirs                      node type, optionally followed by a size comparison
+//	size > 10MB                      also >=, <, <=, =, !=; units B, KB, MB, GB, TB (1024-based)
+//	under ~/Projects                 the path or anything below it
+//	name = x, path != x              exact comparison
+//	name contains x                  case-insensitive substring
+//	name matches *.log               glob
+//	name regex "^IMG_\d+"            regular expression
+//	ext = mp4, ext in (mp4, mov)     extension without the dot, case-insensitive
+//	type, owner, group               = or in, like ext
+//	modified in 30 days              also touched and accessed; units hour, day, week, month, year
+//	modified before 2024-01-01       also after
+//
+// "touched" is the most recent modification or access time anywhere in the
+// node's subtree.
+package query
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+// Predicate reports whether a node matches a compiled query.
+type Predicate func(node *models.FileNode) bool
+
+type Options struct {
+	// Now anchors relative times; zero means the current time
+	Now time.Time
+	// HomeDir expands a leading "~" in paths; empty means the current
+	// user's home directory
+	HomeDir string
+}
+
+// Compile parses input into a Predicate. Errors are *SyntaxError values
+// pointing at the offending position.
+func Compile(input string, options *Options) (Predicate, error) {
+	resolved := Options{}
+	if options != nil {
+		resolved = *options
+	}
+	if resolved.Now.IsZero() {
+		resolved.Now = time.Now()
+	}
+	if resolved.HomeDir == "" {
+		resolved.HomeDir, _ = os.UserHomeDir()
+	}
+	options = &resolved
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, options: options}
+	if p.peek().kind == tokEOF {
+		return nil, &SyntaxError{Pos: 0, Msg: "empty query"}
+	}
+
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %s, expected \",\", \"and\" or \"or\"", tok)
+	}
+	return pred, nil
+}
+
+type parser struct {
+	tokens  []token
+	pos     int
+	options *Options
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// keyword reports whether the next token is the word kw, consuming it if so.
+func (p *parser) keyword(kw string) bool {
+	tok := p.peek()
+	if tok.kind == tokWord && strings.EqualFold(tok.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (Predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		a, b := left, right
+		left = func(node *models.FileNode) bool { return a(node) || b(node) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.peek().kind == tokComma {
+			p.next()
+		} else if !p.keyword("and") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		a, b := left, right
+		left = func(node *models.FileNode) bool { return a(node) && b(node) }
+	}
+}
+
+func (p *parser) parseUnary() (Predicate, error) {
+	if p.keyword("not") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(node *models.FileNode) bool { return !inner(node) }, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Predicate, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "expected \")\", got %s", closing)
+		}
+		return inner, nil
+	case tokWord:
+	default:
+		return nil, p.errorf(tok, "expected a condition, got %s", tok)
+	}
+
+	switch strings.ToLower(tok.text) {
+	case "files", "file":
+		return p.parseTypeShorthand(scanner.FileTypeFile)
+	case "dirs", "dir", "directories", "directory":
+		return p.parseTypeShorthand(scanner.FileTypeDirectory)
+	case "size":
+		return p.parseSizeComparison()
+	case "under":
+		return p.parseUnder()
+	case "name":
+		return p.parseString(func(node *models.FileNode) string { return node.Name })
+	case "path":
+		return p.parseString(func(node *models.FileNode) string { return node.Path })
+	case "ext", "extension":
+		return p.parseSet(func(node *models.FileNode) string { return models.Extension(node.Name) }, true)
+	case "type":
+		return p.parseSet(func(node *models.FileNode) string { return node.Type }, true)
+	case "owner":
+		return p.parseSet(func(node *models.FileNode) string { return node.Owner }, false)
+	case "group":
+		return p.parseSet(func(node *models.FileNode) string { return node.Group }, false)
+	case "modified":
+		return p.parseTime(func(node *models.FileNode) time.Time { return node.LastModified })
+	case "accessed":
+		return p.parseTime(func(node *models.FileNode) time.Time { return node.LastAccessed })
+	case "touched":
+		return p.parseTime(func(node *models.FileNode) time.Time { return models.LastTouched(node, true) })
+	default:
+		return nil, p.errorf(tok, "unknown condition %q", tok.text)
+	}
+}
+
+func (p *parser) parseTypeShorthand(nodeType string) (Predicate, error) {
+	isType := func(node *models.FileNode) bool { return node.Type == nodeType }
+	if p.peek().kind != tokOp {
+		return isType, nil
+	}
+	size, err := p.parseSizeComparison()
+	if err != nil {
+		return nil, err
+	}
+	return func(node *models.FileNode) bool { return isType(node) && size(node) }, nil
+}
+
+func (p *parser) parseSizeComparison() (Predicate, error) {
+	op := p.next()
+	if op.kind != tokOp {
+		return nil, p.errorf(op, "expected a comparison such as \">\", got %s", op)
+	}
+	value := p.next()
+	size, err := p.parseSize(value)
+	if err != nil {
+		return nil, err
+	}
+	compare := compareInt(op.text)
+	return func(node *models.FileNode) bool { return compare(node.Size, size) }, nil
+}
+
+func (p *parser) parseSize(tok token) (int64, error) {
+	if tok.kind != tokWord {
+		return 0, p.errorf(tok, "expected a size such as 100MB, got %s", tok)
+	}
+	text := strings.ToLower(tok.text)
+	split := strings.IndexFunc(text, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	number, unit := text, ""
+	if split >= 0 {
+		number, unit = text[:split], text[split:]
+	}
+	if unit == "" && p.peek().kind == tokWord {
+		if _, ok := sizeUnits[strings.ToLower(p.peek().text)]; ok {
+			unit = strings.ToLower(p.next().text)
+		}
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, p.errorf(tok, "invalid size %q", tok.text)
+	}
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, p.errorf(tok, "unknown size unit %q", unit)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+var sizeUnits = map[string]int64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+func compareInt(op string) func(a, b int64) bool {
+	switch op {
+	case ">":
+		return func(a, b int64) bool { return a > b }
+	case ">=":
+		return func(a, b int64) bool { return a >= b }
+	case "<":
+		return func(a, b int64) bool { return a < b }
+	case "<=":
+		return func(a, b int64) bool { return a <= b }
+	case "!=":
+		return func(a, b int64) bool { return a != b }
+	default:
+		return func(a, b int64) bool { return a == b }
+	}
+}
+
+func (p *parser) value() (token, error) {
+	tok := p.next()
+	if tok.kind != tokWord && tok.kind != tokString {
+		return tok, p.errorf(tok, "expected a value, got %s", tok)
+	}
+	return tok, nil
+}
+
+func (p *parser) parseUnder() (Predicate, error) {
+	tok, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	dir := tok.text
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if p.options.HomeDir == "" {
+			return nil, p.errorf(tok, "cannot expand ~ without a home directory")
+		}
+		dir = p.options.HomeDir + dir[1:]
+	}
+	dir = filepath.Clean(dir)
+	prefix := strings.TrimSuffix(dir, string(filepath.Separator)) + string(filepath.Separator)
+
+	return func(node *models.FileNode) bool {
+		return node.Path == dir || strings.HasPrefix(node.Path, prefix)
+	}, nil
+}
+
+func (p *parser) parseString(field func(*models.FileNode) string) (Predicate, error) {
+	op := p.next()
+	if op.kind == tokWord && strings.EqualFold(op.text, "in") {
+		p.pos--
+		return p.parseSet(field, false)
+	}
+	tok, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	value := tok.text
+
+	switch {
+	case op.kind == tokOp && op.text == "=":
+		return func(node *models.FileNode) bool { return field(node) == value }, nil
+	case op.kind == tokOp && op.text == "!=":
+		return func(node *models.FileNode) bool { return field(node) != value }, nil
+	case op.kind == tokWord && strings.EqualFold(op.text, "contains"):
+		value = strings.ToLower(value)
+		return func(node *models.FileNode) bool {
+			return strings.Contains(strings.ToLower(field(node)), value)
+		}, nil
+	case op.kind == tokWord && strings.EqualFold(op.text, "matches"):
+		if _, err := filepath.Match(value, ""); err != nil {
+			return nil, p.errorf(tok, "invalid glob pattern %q", value)
+		}
+		return func(node *models.FileNode) bool {
+			ok, _ := filepath.Match(value, field(node))
+			return ok
+		}, nil
+	case op.kind == tokWord && strings.EqualFold(op.text, "regex"):
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid regular expression: %v", err)
+		}
+		return func(node *models.FileNode) bool { return re.MatchString(field(node)) }, nil
+	default:
+		return nil, p.errorf(op, "expected =, !=, contains, matches, regex or in, got %s", op)
+	}
+}
+
+func (p *parser) parseSet(field func(*models.FileNode) string, foldCase bool) (Predicate, error) {
+	normalize := func(s string) string {
+		if foldCase {
+			return strings.ToLower(strings.TrimPrefix(s, "."))
+		}
+		return s
+	}
+
+	op := p.next()
+	var values []string
+	negate := false
+	switch {
+	case op.kind == tokOp && (op.text == "=" || op.text == "!="):
+		tok, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = []string{tok.text}
+		negate = op.text == "!="
+	case op.kind == tokWord && strings.EqualFold(op.text, "in"):
+		if open := p.next(); open.kind != tokLParen {
+			return nil, p.errorf(open, "expected \"(\" after in, got %s", open)
+		}
+		for {
+			tok, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, tok.text)
+			sep := p.next()
+			if sep.kind == tokRParen {
+				break
+			}
+			if sep.kind != tokComma {
+				return nil, p.errorf(sep, "expected \",\" or \")\", got %s", sep)
+			}
+		}
+	default:
+		return nil, p.errorf(op, "expected =, != or in, got %s", op)
+	}
+
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[normalize(v)] = true
+	}
+	return func(node *models.FileNode) bool {
+		return set[normalize(field(node))] != negate
+	}, nil
+}
+
+func (p *parser) parseTime(field func(*models.FileNode) time.Time) (Predicate, error) {
+	op := p.next()
+	if op.kind != tokWord {
+		return nil, p.errorf(op, "expected in, before or after, got %s", op)
+	}
+
+	var cutoff time.Time
+	switch strings.ToLower(op.text) {
+	case "in":
+		p.keyword("the")
+		p.keyword("last")
+		var err error
+		if cutoff, err = p.parseDuration(); err != nil {
+			return nil, err
+		}
+		return func(node *models.FileNode) bool { return field(node).After(cutoff) }, nil
+	case "before", "after":
+		tok, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		cutoff, err = time.ParseInLocation("2006-01-02", tok.text, p.options.Now.Location())
+		if err != nil {
+			return nil, p.errorf(tok, "invalid date %q, expected YYYY-MM-DD", tok.text)
+		}
+		if strings.EqualFold(op.text, "before") {
+			return func(node *models.FileNode) bool {
+				t := field(node)
+				return !t.IsZero() && t.Before(cutoff)
+			}, nil
+		}
+		return func(node *models.FileNode) bool { return field(node).After(cutoff) }, nil
+	default:
+		return nil, p.errorf(op, "expected in, before or after, got %s", op)
+	}
+}
+
+// parseDuration reads "30 days", "30d" or "1 year" and returns the point in
+// time that far before now.
+func (p *parser) parseDuration() (time.Time, error) {
+	tok := p.next()
+	if tok.kind != tokWord {
+		return time.Time{}, p.errorf(tok, "expected a duration such as 30 days, got %s", tok)
+	}
+	text := strings.ToLower(tok.text)
+	split := strings.IndexFunc(text, func(r rune) bool { return r < '0' || r > '9' })
+	number, unit := text, ""
+	if split >= 0 {
+		number, unit = text[:split], text[split:]
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return time.Time{}, p.errorf(tok, "invalid duration %q", tok.text)
+	}
+	unitTok := tok
+	if unit == "" {
+		unitTok = p.next()
+		if unitTok.kind != tokWord {
+			return time.Time{}, p.errorf(unitTok, "expected a time unit such as days, got %s", unitTok)
+		}
+		unit = strings.ToLower(unitTok.text)
+	}
+
+	now := p.options.Now
+	switch strings.TrimSuffix(unit, "s") {
+	case "h", "hour":
+		return now.Add(-time.Duration(n) * time.Hour), nil
+	case "d", "day":
+		return now.AddDate(0, 0, -n), nil
+	case "w", "week":
+		return now.AddDate(0, 0, -7*n), nil
+	case "mo", "month":
+		return now.AddDate(0, -n, 0), nil
+	case "y", "year":
+		return now.AddDate(-n, 0, 0), nil
+	default:
+		return time.Time{}, p.errorf(unitTok, "unknown time unit %q", unit)
+	}
+}
@@ -0,0 +1,155 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+var testNow = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func scanTestTree(t *testing.T) *models.FileNode {
+	t.Helper()
+	m := vfs.NewMemFS()
+	files := []struct {
+		path     string
+		size     int
+		modified time.Time
+	}{
+		{"/home/me/Projects/film/raw.MOV", 300 << 20, testNow.AddDate(-2, 0, 0)},
+		{"/home/me/Projects/film/final.mp4", 150 << 20, testNow.AddDate(0, -1, 0)},
+		{"/home/me/Projects/film/notes.txt", 2 << 10, testNow.AddDate(-3, 0, 0)},
+		{"/home/me/Projects/old/demo.mp4", 120 << 20, testNow.AddDate(-1, -6, 0)},
+		{"/home/me/Videos/holiday.mp4", 500 << 20, testNow.AddDate(-5, 0, 0)},
+		{"/home/me/.bashrc", 1 << 10, testNow.AddDate(0, 0, -2)},
+	}
+	for _, f := range files {
+		_ = m.WriteFile(f.path, make([]byte, f.size))
+		_ = m.Chtimes(f.path, f.modified)
+	}
+	_ = m.Chown("/home/me/Videos/holiday.mp4", 1001, 1001)
+	m.AddUser(1001, "guest")
+
+	options := scanner.DefaultScanOptions()
+	options.ShowHiddenFiles = true
+	options.MaxFileSize = 0
+	result, err := scanner.NewScannerWithFS(m, options).ScanPath("/home/me", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	return result.Root
+}
+
+func matchingFiles(root *models.FileNode, match Predicate) []string {
+	var paths []string
+	var walk func(node *models.FileNode)
+	walk = func(node *models.FileNode) {
+		if node.Type == scanner.FileTypeFile && match(node) {
+			paths = append(paths, node.Path)
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return paths
+}
+
+func TestCompile(t *testing.T) {
+	root := scanTestTree(t)
+	options := &Options{Now: testNow, HomeDir: "/home/me"}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{
+			"files > 100MB, not touched in 1 year, under ~/Projects, extension in (mp4,mov)",
+			[]string{"/home/me/Projects/film/raw.MOV", "/home/me/Projects/old/demo.mp4"},
+		},
+		{"size <= 2KB and name matches '.*'", []string{"/home/me/.bashrc"}},
+		{"ext = mp4 or name contains notes", []string{"/home/me/Projects/film/final.mp4", "/home/me/Projects/film/notes.txt", "/home/me/Projects/old/demo.mp4", "/home/me/Videos/holiday.mp4"}},
+		{"modified in the last 30 days", []string{"/home/me/.bashrc"}},
+		{"modified before 2021-01-01", []string{"/home/me/Videos/holiday.mp4"}},
+		{`owner = guest`, []string{"/home/me/Videos/holiday.mp4"}},
+		{`path regex "/film/[a-z]+\.mp4$"`, []string{"/home/me/Projects/film/final.mp4"}},
+		{"not (under ~/Projects or under ~/Videos)", []string{"/home/me/.bashrc"}},
+		{"files >= 1.5 GB", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			match, err := Compile(tt.query, options)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			got := matchingFiles(root, match)
+			if len(got) != len(tt.want) {
+				t.Fatalf("matches = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("matches = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{"", 0},
+		{"files > 100XB", 8},
+		{"sise > 1MB", 0},
+		{"files > 1MB,", 12},
+		{"ext in (mp4, mov", 16},
+		{"touched in 3 fortnights", 13},
+		{"name regex '('", 11},
+		{"(files", 6},
+		{"files ! dirs", 6},
+		{"name = 'x", 7},
+		{"modified before 2024-13-01", 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Compile(tt.query, &Options{Now: testNow})
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Compile() error = %v, want SyntaxError", err)
+			}
+			if syntaxErr.Pos != tt.pos {
+				t.Errorf("Pos = %d, want %d (%v)", syntaxErr.Pos, tt.pos, err)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	root := scanTestTree(t)
+	match, err := Compile("ext in (mp4, mov), under /home/me/Projects", &Options{Now: testNow})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	filtered := models.Filter(root, match)
+	if want := int64(570 << 20); filtered.Size != want {
+		t.Errorf("Size = %d, want %d", filtered.Size, want)
+	}
+	if len(filtered.Children) != 1 || filtered.Children[0].Name != "Projects" {
+		t.Fatalf("Children = %+v, want only Projects", filtered.Children)
+	}
+	film := filtered.Children[0].Children[0]
+	if film.Name != "film" || len(film.Children) != 2 || film.Size != 450<<20 {
+		t.Errorf("film = %d children, size %d", len(film.Children), film.Size)
+	}
+	if root.Size == filtered.Size {
+		t.Error("Filter modified the original tree")
+	}
+}
@@ -0,0 +1,98 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"vizdisk/internal/models"
+)
+
+// PNG writes the chart as a PNG image.
+func PNG(w io.Writer, result *models.ScanResult, options *Options) error {
+	img, err := Image(result, options)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Image rasterizes the chart for callers that want to encode it themselves.
+func Image(result *models.ScanResult, options *Options) (*image.RGBA, error) {
+	s, err := buildScene(result, options)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, s.width, s.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	for _, r := range s.rects {
+		// Leave a one pixel gap on the right and bottom so neighbours with
+		// the same colour stay distinguishable
+		x0, y0 := int(math.Round(r.x)), int(math.Round(r.y))
+		x1, y1 := int(math.Round(r.x+r.w))-1, int(math.Round(r.y+r.h))-1
+		if x1 <= x0 || y1 <= y0 {
+			continue
+		}
+		draw.Draw(img, image.Rect(x0, y0, x1, y1), image.NewUniform(r.fill), image.Point{}, draw.Src)
+	}
+
+	if s.disc != nil {
+		rasterizeSunburst(img, s)
+	}
+
+	drawer := &font.Drawer{Dst: img, Face: basicfont.Face7x13}
+	for _, t := range s.texts {
+		drawer.Src = image.NewUniform(t.fill)
+		drawer.Dot = fixed.P(int(math.Round(t.x)), int(math.Round(t.y)))
+		drawer.DrawString(t.text)
+	}
+	return img, nil
+}
+
+// rasterizeSunburst colours each pixel of the chart by finding the wedge of
+// its ring that covers its angle.
+func rasterizeSunburst(img *image.RGBA, s *scene) {
+	var rings [][]*wedgeShape
+	for _, wedge := range s.wedges {
+		for len(rings) <= wedge.depth {
+			rings = append(rings, nil)
+		}
+		rings[wedge.depth] = append(rings[wedge.depth], wedge)
+	}
+	ring := s.disc.r1
+	outer := ring * float64(len(rings))
+
+	minX, maxX := int(s.cx-outer)-1, int(s.cx+outer)+1
+	minY, maxY := int(s.cy-outer)-1, int(s.cy+outer)+1
+	for y := max(minY, 0); y <= min(maxY, s.height-1); y++ {
+		for x := max(minX, 0); x <= min(maxX, s.width-1); x++ {
+			dx, dy := float64(x)+0.5-s.cx, float64(y)+0.5-s.cy
+			r := math.Hypot(dx, dy)
+			depth := int(r / ring)
+			if depth == 0 || depth >= len(rings) {
+				continue
+			}
+			a := math.Atan2(dx, -dy)
+			if a < 0 {
+				a += 2 * math.Pi
+			}
+			// Wedges of a ring are appended in angle order
+			wedges := rings[depth]
+			i := sort.Search(len(wedges), func(i int) bool { return wedges[i].a1 > a })
+			// Leave the start edge of each wedge and ring blank as a
+			// separator, like the SVG stroke
+			if i < len(wedges) && a >= wedges[i].a0 && (a-wedges[i].a0)*r >= 0.75 && r-float64(depth)*ring >= 0.75 {
+				img.SetRGBA(x, y, wedges[i].fill)
+			}
+		}
+	}
+}
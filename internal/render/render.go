@@ -0,0 +1,308 @@
+// Package render draws a scan as a treemap or sunburst picture without the
+// GUI, as SVG or PNG.
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"vizdisk/internal/humanize"
+	"vizdisk/internal/models"
+	"vizdisk/internal/treemap"
+)
+
+const (
+	ChartTreemap  = "treemap"
+	ChartSunburst = "sunburst"
+
+	headerHeight = 24
+	legendHeight = 24
+	// Text metrics of the 7x13 bitmap font used for PNG; SVG text is sized
+	// to match so that both formats place the same labels
+	charWidth  = 7
+	lineHeight = 13
+)
+
+type Options struct {
+	Chart    string `json:"chart"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	MaxDepth int    `json:"maxDepth"`
+	// Title defaults to the scanned path and its total size
+	Title string `json:"title"`
+}
+
+func DefaultOptions() *Options {
+	return &Options{
+		Chart:    ChartTreemap,
+		Width:    1200,
+		Height:   800,
+		MaxDepth: 4,
+	}
+}
+
+type rectShape struct {
+	x, y, w, h float64
+	fill       color.RGBA
+	title      string
+}
+
+// wedgeShape is a ring segment. Angles are in radians clockwise from
+// twelve o'clock.
+type wedgeShape struct {
+	r0, r1, a0, a1 float64
+	depth          int
+	fill           color.RGBA
+	title          string
+}
+
+type textShape struct {
+	x, y  float64
+	text  string
+	fill  color.RGBA
+	large bool
+}
+
+// scene is the format-independent drawing both encoders render.
+type scene struct {
+	width, height int
+	cx, cy        float64
+	disc          *wedgeShape
+	rects         []*rectShape
+	wedges        []*wedgeShape
+	texts         []*textShape
+}
+
+var (
+	background = color.RGBA{255, 255, 255, 255}
+	darkText   = color.RGBA{17, 24, 39, 255}
+	lightText  = color.RGBA{255, 255, 255, 255}
+)
+
+// buckets are the size ratios, relative to the largest top-level entry, at
+// which the frontend charts switch colours.
+var buckets = []float64{0.7, 0.5, 0.3, 0.15, 0}
+
+func bucket(size, largest int64) int {
+	if largest <= 0 {
+		return len(buckets) - 1
+	}
+	ratio := float64(size) / float64(largest)
+	for i, threshold := range buckets {
+		if ratio > threshold {
+			return i
+		}
+	}
+	return len(buckets) - 1
+}
+
+// treemapColor mirrors TreeMapChart's grey scale.
+func treemapColor(b int) (fill, text color.RGBA) {
+	lightness := []float64{4.1, 46.1, 69, 85.9, 92}
+	saturation := []float64{71.4, 8.9, 13, 14.3, 40}
+	hue := []float64{224, 220, 220, 220, 210}
+	fill = hsl(hue[b], saturation[b], lightness[b])
+	if b < 2 {
+		return fill, lightText
+	}
+	return fill, darkText
+}
+
+// sunburstColor mirrors SunburstChart, rotating the hue with each ring.
+func sunburstColor(b, level int) (fill, text color.RGBA) {
+	lightness := []float64{20, 35, 50, 65, 80}
+	saturation := []float64{71.4, 60, 50, 40, 30}
+	fill = hsl(math.Mod(220+float64(level)*30, 360), saturation[b], lightness[b])
+	if b < 3 {
+		return fill, lightText
+	}
+	return fill, darkText
+}
+
+func hsl(h, s, l float64) color.RGBA {
+	s /= 100
+	l /= 100
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := h / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g = c, x
+	case hp < 2:
+		r, g = x, c
+	case hp < 3:
+		g, b = c, x
+	case hp < 4:
+		g, b = x, c
+	case hp < 5:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	m := l - c/2
+	to8 := func(v float64) uint8 { return uint8(math.Round((v + m) * 255)) }
+	return color.RGBA{to8(r), to8(g), to8(b), 255}
+}
+
+func textWidth(s string) float64 {
+	return float64(len([]rune(s)) * charWidth)
+}
+
+func buildScene(result *models.ScanResult, options *Options) (*scene, error) {
+	if result == nil || result.Root == nil {
+		return nil, fmt.Errorf("no scan result to render")
+	}
+	if options == nil {
+		options = DefaultOptions()
+	}
+	if options.Width < 200 || options.Height < 150 {
+		return nil, fmt.Errorf("image size %dx%d is too small", options.Width, options.Height)
+	}
+
+	s := &scene{width: options.Width, height: options.Height}
+	title := options.Title
+	if title == "" {
+		title = fmt.Sprintf("%s - %s in %d files", result.Root.Path, humanize.Bytes(result.TotalSize), result.TotalFiles)
+	}
+	s.texts = append(s.texts, &textShape{x: 8, y: 17, text: title, fill: darkText, large: true})
+
+	var largest int64
+	for _, child := range result.Root.Children {
+		largest = max(largest, child.Size)
+	}
+
+	chartHeight := float64(options.Height - headerHeight - legendHeight)
+	switch options.Chart {
+	case "", ChartTreemap:
+		s.addTreemap(result.Root, float64(options.Width), chartHeight, options.MaxDepth, largest)
+		s.addLegend(largest, func(b int) color.RGBA { fill, _ := treemapColor(b); return fill })
+	case ChartSunburst:
+		s.addSunburst(result.Root, float64(options.Width), chartHeight, options.MaxDepth, largest)
+		s.addLegend(largest, func(b int) color.RGBA { fill, _ := sunburstColor(b, 1); return fill })
+	default:
+		return nil, fmt.Errorf("unknown chart type: %s", options.Chart)
+	}
+	return s, nil
+}
+
+func (s *scene) addTreemap(root *models.FileNode, width, height float64, maxDepth int, largest int64) {
+	tiles := treemap.Layout(root, &treemap.Options{
+		Width:    width,
+		Height:   height,
+		MaxDepth: maxDepth,
+		MinArea:  9,
+		Padding:  1,
+		Header:   lineHeight + 2,
+	})
+
+	for _, tile := range tiles {
+		if tile.Depth == 0 {
+			continue
+		}
+		fill, text := treemapColor(bucket(tile.Size, largest))
+		y := tile.Y + headerHeight
+		s.rects = append(s.rects, &rectShape{
+			x: tile.X, y: y, w: tile.W, h: tile.H,
+			fill:  fill,
+			title: fmt.Sprintf("%s (%s)", tile.Path, humanize.Bytes(tile.Size)),
+		})
+
+		if tile.H < lineHeight+2 {
+			continue
+		}
+		label := tile.Name
+		if tile.W >= textWidth(label+" "+humanize.Bytes(tile.Size))+6 {
+			label += " " + humanize.Bytes(tile.Size)
+		}
+		if tile.W >= textWidth(label)+6 {
+			s.texts = append(s.texts, &textShape{x: tile.X + 3, y: y + lineHeight - 1, text: label, fill: text})
+		}
+	}
+}
+
+func (s *scene) addSunburst(root *models.FileNode, width, height float64, maxDepth int, largest int64) {
+	if maxDepth <= 0 {
+		maxDepth = 4
+	}
+	maxDepth = min(maxDepth, treeDepth(root))
+	s.cx, s.cy = width/2, headerHeight+height/2
+	radius := math.Min(width, height)/2 - 4
+	ring := radius / float64(maxDepth+1)
+
+	s.disc = &wedgeShape{r1: ring, a1: 2 * math.Pi, fill: background}
+	s.texts = append(s.texts, &textShape{
+		x:    s.cx - textWidth(humanize.Bytes(root.Size))/2,
+		y:    s.cy + 4,
+		text: humanize.Bytes(root.Size),
+		fill: darkText,
+	})
+
+	var visit func(node *models.FileNode, depth int, a0, a1 float64)
+	visit = func(node *models.FileNode, depth int, a0, a1 float64) {
+		if depth > maxDepth || node.Size <= 0 {
+			return
+		}
+		children := append([]*models.FileNode(nil), node.Children...)
+		sort.SliceStable(children, func(i, j int) bool { return children[i].Size > children[j].Size })
+
+		a := a0
+		for _, child := range children {
+			if child.Size <= 0 {
+				continue
+			}
+			span := (a1 - a0) * float64(child.Size) / float64(node.Size)
+			r0, r1 := float64(depth)*ring, float64(depth+1)*ring
+			if span*r1 < 1 {
+				// Children are sorted, so the rest are narrower still
+				break
+			}
+			fill, text := sunburstColor(bucket(child.Size, largest), depth)
+			s.wedges = append(s.wedges, &wedgeShape{
+				r0: r0, r1: r1, a0: a, a1: a + span,
+				depth: depth,
+				fill:  fill,
+				title: fmt.Sprintf("%s (%s)", child.Path, humanize.Bytes(child.Size)),
+			})
+
+			mid, midR := a+span/2, (r0+r1)/2
+			if span*midR >= textWidth(child.Name)+6 && ring >= textWidth(child.Name)/2 {
+				s.texts = append(s.texts, &textShape{
+					x:    s.cx + midR*math.Sin(mid) - textWidth(child.Name)/2,
+					y:    s.cy - midR*math.Cos(mid) + 4,
+					text: child.Name,
+					fill: text,
+				})
+			}
+
+			visit(child, depth+1, a, a+span)
+			a += span
+		}
+	}
+	visit(root, 1, 0, 2*math.Pi)
+}
+
+func treeDepth(node *models.FileNode) int {
+	depth := 0
+	for _, child := range node.Children {
+		depth = max(depth, treeDepth(child)+1)
+	}
+	return depth
+}
+
+// addLegend explains the colour buckets in terms of sizes.
+func (s *scene) addLegend(largest int64, colorOf func(int) color.RGBA) {
+	x := 8.0
+	y := float64(s.height - legendHeight + 6)
+	for b, threshold := range buckets {
+		label := "> " + humanize.Bytes(int64(threshold*float64(largest)))
+		if threshold == 0 {
+			label = "smaller"
+		}
+		s.rects = append(s.rects, &rectShape{x: x, y: y, w: 12, h: 12, fill: colorOf(b)})
+		s.texts = append(s.texts, &textShape{x: x + 16, y: y + 10, text: label, fill: darkText})
+		x += 16 + textWidth(label) + 16
+	}
+}
@@ -0,0 +1,88 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"strings"
+	"testing"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+func scanTestTree(t *testing.T) *models.ScanResult {
+	t.Helper()
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/videos/holiday.mp4", make([]byte, 60000))
+	_ = m.WriteFile("/data/videos/clip.mov", make([]byte, 20000))
+	_ = m.WriteFile("/data/docs/a&b.pdf", make([]byte, 15000))
+	_ = m.WriteFile("/data/notes.txt", make([]byte, 5000))
+	result, err := scanner.NewScannerWithFS(m, scanner.DefaultScanOptions()).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	return result
+}
+
+func TestSVG(t *testing.T) {
+	result := scanTestTree(t)
+
+	for _, chart := range []string{ChartTreemap, ChartSunburst} {
+		t.Run(chart, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := SVG(&buf, result, &Options{Chart: chart, Width: 800, Height: 600, MaxDepth: 3}); err != nil {
+				t.Fatalf("SVG() error = %v", err)
+			}
+
+			decoder := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+			for {
+				if _, err := decoder.Token(); err != nil {
+					if err.Error() != "EOF" {
+						t.Fatalf("SVG is not well-formed: %v", err)
+					}
+					break
+				}
+			}
+
+			svg := buf.String()
+			for _, want := range []string{"holiday.mp4", "a&amp;b.pdf", "/data - 97.66 KB in 4 files", "smaller"} {
+				if !strings.Contains(svg, want) {
+					t.Errorf("SVG does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	result := scanTestTree(t)
+
+	for _, chart := range []string{ChartTreemap, ChartSunburst} {
+		var buf bytes.Buffer
+		if err := PNG(&buf, result, &Options{Chart: chart, Width: 400, Height: 300}); err != nil {
+			t.Fatalf("PNG(%s) error = %v", chart, err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("png.Decode() error = %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != 400 || b.Dy() != 300 {
+			t.Errorf("bounds = %v, want 400x300", b)
+		}
+		// The chart centre is covered by a tile, or right of it by the first
+		// sunburst ring, which spans 41-81px for this two level tree
+		x, y := 200, headerHeight+(300-headerHeight-legendHeight)/2
+		if chart == ChartSunburst {
+			x += 60
+		}
+		if r, g, b, _ := img.At(x, y).RGBA(); r == 0xffff && g == 0xffff && b == 0xffff {
+			t.Errorf("%s: chart area is blank", chart)
+		}
+	}
+
+	if err := PNG(&bytes.Buffer{}, result, &Options{Chart: "pie", Width: 400, Height: 300}); err == nil {
+		t.Error("unknown chart type should fail")
+	}
+}
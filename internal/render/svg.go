@@ -0,0 +1,97 @@
+package render
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+
+	"vizdisk/internal/models"
+)
+
+// SVG writes the chart as a standalone SVG document. Every shape carries a
+// <title> with its path and size, which viewers show as a tooltip.
+func SVG(w io.Writer, result *models.ScanResult, options *Options) error {
+	s, err := buildScene(result, options)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		s.width, s.height, s.width, s.height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(background))
+
+	for _, r := range s.rects {
+		fmt.Fprintf(bw, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s" stroke="#ffffff" stroke-width="0.5">`,
+			r.x, r.y, r.w, r.h, hex(r.fill))
+		writeTitle(bw, r.title)
+		bw.WriteString("</rect>\n")
+	}
+
+	if s.disc != nil {
+		fmt.Fprintf(bw, `<circle cx="%.2f" cy="%.2f" r="%.2f" fill="%s" stroke="#d1d5db"/>`+"\n",
+			s.cx, s.cy, s.disc.r1, hex(s.disc.fill))
+	}
+	for _, wedge := range s.wedges {
+		fmt.Fprintf(bw, `<path d="%s" fill="%s" stroke="#ffffff" stroke-width="0.5">`, wedgePath(s.cx, s.cy, wedge), hex(wedge.fill))
+		writeTitle(bw, wedge.title)
+		bw.WriteString("</path>\n")
+	}
+
+	for _, t := range s.texts {
+		size, weight := 11, "normal"
+		if t.large {
+			size, weight = 13, "bold"
+		}
+		fmt.Fprintf(bw, `<text x="%.2f" y="%.2f" font-family="monospace" font-size="%d" font-weight="%s" fill="%s">%s</text>`+"\n",
+			t.x, t.y, size, weight, hex(t.fill), escape(t.text))
+	}
+
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+func writeTitle(w *bufio.Writer, title string) {
+	if title != "" {
+		w.WriteString("<title>" + escape(title) + "</title>")
+	}
+}
+
+// wedgePath outlines a ring segment, splitting arcs longer than half a turn
+// so that full rings draw correctly.
+func wedgePath(cx, cy float64, wedge *wedgeShape) string {
+	point := func(r, a float64) string {
+		return fmt.Sprintf("%.2f %.2f", cx+r*math.Sin(a), cy-r*math.Cos(a))
+	}
+	angles := []float64{wedge.a0}
+	if wedge.a1-wedge.a0 > math.Pi {
+		angles = append(angles, (wedge.a0+wedge.a1)/2)
+	}
+	angles = append(angles, wedge.a1)
+
+	var b strings.Builder
+	b.WriteString("M" + point(wedge.r1, angles[0]))
+	for _, a := range angles[1:] {
+		fmt.Fprintf(&b, " A%.2f %.2f 0 0 1 %s", wedge.r1, wedge.r1, point(wedge.r1, a))
+	}
+	b.WriteString(" L" + point(wedge.r0, angles[len(angles)-1]))
+	for i := len(angles) - 2; i >= 0; i-- {
+		fmt.Fprintf(&b, " A%.2f %.2f 0 0 0 %s", wedge.r0, wedge.r0, point(wedge.r0, angles[i]))
+	}
+	b.WriteString(" Z")
+	return b.String()
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"vizdisk/internal/humanize"
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+//go:embed report.html
+var htmlSource string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"bytes":   humanize.Bytes,
+	"percent": humanize.Percent,
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04")
+	},
+}).Parse(htmlSource))
+
+// treeNode is the compact form of the tree embedded in the page.
+type treeNode struct {
+	Name     string      `json:"n"`
+	Size     int64       `json:"s"`
+	Dir      bool        `json:"d,omitempty"`
+	Merged   int         `json:"m,omitempty"` // entries merged into this one
+	Children []*treeNode `json:"c,omitempty"`
+}
+
+type htmlData struct {
+	*summary
+	Tree      *treeNode
+	Separator string
+}
+
+// HTML writes a single page with an interactive treemap, the largest files
+// and directories, the type breakdown and cleanup suggestions. Styles,
+// scripts and data are inlined so that it opens offline.
+func HTML(w io.Writer, result *models.ScanResult, options *Options) error {
+	s, options, err := summarize(result, options)
+	if err != nil {
+		return err
+	}
+
+	separator := "/"
+	if strings.Contains(result.Root.Path, `\`) {
+		separator = `\`
+	}
+	threshold := int64(float64(result.Root.Size) * options.MinShare)
+	data := &htmlData{
+		summary:   s,
+		Tree:      prune(result.Root, 0, options.MaxDepth, threshold),
+		Separator: separator,
+	}
+	data.Tree.Name = result.Root.Path
+
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// prune copies the tree down to maxDepth, merging the children of each
+// directory that are smaller than threshold into a single entry.
+func prune(node *models.FileNode, depth, maxDepth int, threshold int64) *treeNode {
+	pruned := &treeNode{Name: node.Name, Size: node.Size, Dir: node.Type == scanner.FileTypeDirectory}
+	if !pruned.Dir || depth >= maxDepth {
+		return pruned
+	}
+
+	children := make([]*models.FileNode, 0, len(node.Children))
+	for _, child := range node.Children {
+		if !child.IsVirtual && child.Size > 0 {
+			children = append(children, child)
+		}
+	}
+	sort.SliceStable(children, func(i, j int) bool { return children[i].Size > children[j].Size })
+
+	var rest *treeNode
+	for _, child := range children {
+		if child.Size >= threshold {
+			pruned.Children = append(pruned.Children, prune(child, depth+1, maxDepth, threshold))
+			continue
+		}
+		if rest == nil {
+			rest = &treeNode{}
+		}
+		rest.Size += child.Size
+		rest.Merged++
+	}
+	if rest != nil {
+		rest.Name = fmt.Sprintf("%d smaller items", rest.Merged)
+		pruned.Children = append(pruned.Children, rest)
+	}
+	return pruned
+}
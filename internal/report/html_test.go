@@ -0,0 +1,100 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"vizdisk/internal/analyzer"
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+func scanTestTree(t *testing.T) *models.ScanResult {
+	t.Helper()
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/home/me/videos/holiday.mp4", make([]byte, 60000))
+	_ = m.WriteFile("/home/me/web/package.json", make([]byte, 100))
+	_ = m.WriteFile("/home/me/web/node_modules/react/index.js", make([]byte, 20000))
+	_ = m.WriteFile("/home/me/notes/<b>&.txt", make([]byte, 5000))
+	for _, name := range []string{"a", "b", "c"} {
+		_ = m.WriteFile("/home/me/notes/tiny-"+name+".txt", make([]byte, 10))
+	}
+
+	result, err := scanner.NewScannerWithFS(m, scanner.DefaultScanOptions()).ScanPath("/home/me", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	result.FileTypes = analyzer.NewFileTypeAnalyzer(m, nil).Analyze(result.Root)
+	return result
+}
+
+func TestHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := HTML(&buf, scanTestTree(t), &Options{Title: "Home & away", MinShare: 0.01}); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	page := buf.String()
+
+	for _, want := range []string{
+		"<title>Home &amp; away</title>",
+		"/home/me/videos/holiday.mp4",
+		"/home/me/notes/&lt;b&gt;&amp;.txt",
+		"/home/me/web/node_modules",
+		"npm/yarn dependencies",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+	for _, external := range []string{"http://", "https://", "src=", "<link"} {
+		if strings.Contains(page, external) {
+			t.Errorf("report references external resources: found %q", external)
+		}
+	}
+
+	// The tree is embedded as a JavaScript literal
+	start := strings.Index(page, "var tree = ")
+	if start < 0 {
+		t.Fatal("tree data not found")
+	}
+	data := page[start+len("var tree = "):]
+	data = data[:strings.Index(data, ";\n")]
+	var tree treeNode
+	if err := json.Unmarshal([]byte(data), &tree); err != nil {
+		t.Fatalf("tree data is not JSON: %v\n%s", err, data)
+	}
+	if tree.Name != "/home/me" || tree.Size != 85130 || len(tree.Children) != 3 {
+		t.Errorf("tree = %s %d with %d children", tree.Name, tree.Size, len(tree.Children))
+	}
+	if strings.Contains(data, "<b>") {
+		t.Error("tree data is not escaped for a script element")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	root := scanTestTree(t).Root
+
+	tree := prune(root, 0, 6, 1000)
+	var notes *treeNode
+	for _, child := range tree.Children {
+		if child.Name == "notes" {
+			notes = child
+		}
+	}
+	if notes == nil || len(notes.Children) != 2 {
+		t.Fatalf("notes = %+v, want one file and the merged entry", notes)
+	}
+	if merged := notes.Children[1]; merged.Merged != 3 || merged.Size != 30 || merged.Name != "3 smaller items" {
+		t.Errorf("merged = %+v", merged)
+	}
+
+	shallow := prune(root, 0, 1, 0)
+	for _, child := range shallow.Children {
+		if child.Children != nil {
+			t.Errorf("%s has children beyond the maximum depth", child.Name)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"vizdisk/internal/analyzer"
+	"vizdisk/internal/humanize"
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+const barWidth = 20
+
+// Markdown writes a concise GitHub-flavoured summary for pull requests and
+// wikis: totals, the largest directories and files, the type breakdown and,
+// when previous is not nil, what changed since that scan.
+func Markdown(w io.Writer, result *models.ScanResult, previous *models.ScanResult, options *Options) error {
+	s, options, err := summarize(result, options)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", escape(s.Title))
+	fmt.Fprintf(&b, "Scanned %s on %s.\n\n", code(s.Path), s.ScanTime.Format("2006-01-02 15:04"))
+	b.WriteString("| Total size | Files | Directories | Reclaimable |\n|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %s | %d | %d | %s |\n", humanize.Bytes(s.TotalSize), s.TotalFiles, s.TotalDirectories, humanize.Bytes(s.Cleanup.TotalSize))
+
+	if len(s.TopDirectories) > 0 {
+		b.WriteString("\n## Largest directories\n\n| Directory | Size | Share | |\n|---|---:|---:|---|\n")
+		for _, dir := range s.TopDirectories {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", code(dir.Path), humanize.Bytes(dir.Size), humanize.Percent(dir.Size, s.TotalSize), bar(dir.Size, s.TotalSize))
+		}
+	}
+
+	if len(s.TopFiles) > 0 {
+		b.WriteString("\n## Largest files\n\n| File | Size | Modified |\n|---|---:|---:|\n")
+		for _, file := range s.TopFiles {
+			modified := "-"
+			if !file.LastModified.IsZero() {
+				modified = file.LastModified.Format("2006-01-02")
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", code(file.Path), humanize.Bytes(file.Size), modified)
+		}
+	}
+
+	if len(s.Categories) > 0 {
+		b.WriteString("\n## File types\n\n| Category | Files | Size | Share | |\n|---|---:|---:|---:|---|\n")
+		for _, category := range s.Categories {
+			fmt.Fprintf(&b, "| %s | %d | %s | %s | %s |\n", escape(category.Key), category.Count, humanize.Bytes(category.Size), humanize.Percent(category.Size, s.TotalSize), bar(category.Size, s.TotalSize))
+		}
+	}
+
+	if previous != nil && previous.Root != nil {
+		writeChanges(&b, analyzer.Diff(previous, result, nil), options.TopN)
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// writeChanges lists the largest changes as whole added or removed
+// directories and individual files, so that no change is counted twice.
+func writeChanges(b *strings.Builder, diff *analyzer.DiffReport, limit int) {
+	root := diff.Root
+	fmt.Fprintf(b, "\n## Changes since %s\n\n", diff.OldScanTime.Format("2006-01-02 15:04"))
+	fmt.Fprintf(b, "Total size went from %s to %s (%s): %s added, %s removed, %s grown and %s shrunk.\n",
+		humanize.Bytes(root.OldSize), humanize.Bytes(root.NewSize), signed(root.Delta),
+		humanize.Bytes(root.Added), humanize.Bytes(root.Removed), humanize.Bytes(root.Grown), humanize.Bytes(root.Shrunk))
+
+	var changes []*analyzer.DeltaNode
+	var collect func(node *analyzer.DeltaNode)
+	collect = func(node *analyzer.DeltaNode) {
+		whole := node.Status == analyzer.DiffAdded || node.Status == analyzer.DiffRemoved
+		if node.Type != scanner.FileTypeDirectory || (whole && node != root) {
+			if node.Delta != 0 {
+				changes = append(changes, node)
+			}
+			return
+		}
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	collect(root)
+	if len(changes) == 0 {
+		b.WriteString("\nNo files were added, removed or resized.\n")
+		return
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return abs(changes[i].Delta) > abs(changes[j].Delta) })
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+	b.WriteString("\n| Path | Change | Before | After | Delta |\n|---|---|---:|---:|---:|\n")
+	for _, change := range changes {
+		path := change.Path
+		if change.Type == scanner.FileTypeDirectory {
+			path += "/"
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n", code(path), change.Status, humanize.Bytes(change.OldSize), humanize.Bytes(change.NewSize), signed(change.Delta))
+	}
+}
+
+// bar draws value as a share of total with block characters.
+func bar(value, total int64) string {
+	filled := 0
+	if total > 0 {
+		filled = int(float64(value)/float64(total)*barWidth + 0.5)
+	}
+	filled = min(max(filled, 0), barWidth)
+	return strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+}
+
+func signed(n int64) string {
+	if n > 0 {
+		return "+" + humanize.Bytes(n)
+	}
+	return humanize.Bytes(n)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// escape keeps names from being read as Markdown or breaking table cells.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', '`', '*', '_', '[', ']', '<', '>', '|', '#', '~':
+			b.WriteByte('\\')
+		case '\n', '\r':
+			r = ' '
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// code formats a path as inline code, which renders it in full without
+// escapes; only the table separator has to be escaped inside the span.
+func code(s string) string {
+	s = strings.NewReplacer("|", `\|`, "\n", " ", "\r", " ").Replace(s)
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Markdown(&buf, scanTestTree(t), nil, &Options{TopN: 3}); err != nil {
+		t.Fatalf("Markdown() error = %v", err)
+	}
+	md := buf.String()
+
+	for _, want := range []string{
+		"# Disk usage of /home/me\n",
+		"| 83.13 KB | 7 | 6 | 19.53 KB |",
+		"| `/home/me/videos` | 58.59 KB | 70.5% | ██████████████░░░░░░ |",
+		"| `/home/me/notes/<b>&.txt` | 4.88 KB |",
+		"## File types",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("report does not contain %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "/home/me/notes/tiny-a.txt") {
+		t.Error("largest files are not limited to TopN")
+	}
+	if strings.Contains(md, "## Changes") {
+		t.Error("changes reported without a previous scan")
+	}
+}
+
+func TestMarkdown_Changes(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/home/me/videos/holiday.mp4", make([]byte, 40000))
+	_ = m.WriteFile("/home/me/old/backup.zip", make([]byte, 7000))
+	_ = m.WriteFile("/home/me/old/list.txt", make([]byte, 1000))
+	_ = m.WriteFile("/home/me/notes/<b>&.txt", make([]byte, 5000))
+	previous, err := scanner.NewScannerWithFS(m, scanner.DefaultScanOptions()).ScanPath("/home/me", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Markdown(&buf, scanTestTree(t), previous, nil); err != nil {
+		t.Fatalf("Markdown() error = %v", err)
+	}
+	md := buf.String()
+
+	for _, want := range []string{
+		"Total size went from 51.76 KB to 83.13 KB (+31.38 KB): 19.66 KB added, 7.81 KB removed, 19.53 KB grown and 0.00 B shrunk.",
+		"| `/home/me/videos/holiday.mp4` | grown | 39.06 KB | 58.59 KB | +19.53 KB |",
+		"| `/home/me/web/` | added | 0.00 B | 19.63 KB | +19.63 KB |",
+		"| `/home/me/old/` | removed | 7.81 KB | 0.00 B | -7.81 KB |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("report does not contain %q:\n%s", want, md)
+		}
+	}
+	if changes := md[strings.Index(md, "## Changes"):]; strings.Contains(changes, "index.js") {
+		t.Error("files of an added directory are listed on their own")
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := map[string]string{
+		"/a/b":     "`/a/b`",
+		"a|b":      "`a\\|b`",
+		"it`s":     "``it`s``",
+		"`quoted`": "`` `quoted` ``",
+	}
+	for in, want := range tests {
+		if got := code(in); got != want {
+			t.Errorf("code(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := escape("*big* files_1"); got != `\*big\* files\_1` {
+		t.Errorf("escape() = %q", got)
+	}
+}
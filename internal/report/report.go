@@ -0,0 +1,105 @@
+// Package report summarizes a scan as a document that can be shared with
+// people who do not run VizDisk: a self-contained HTML page or Markdown.
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"vizdisk/internal/analyzer"
+	"vizdisk/internal/models"
+)
+
+type Options struct {
+	// Title defaults to "Disk usage of" and the scanned path
+	Title string `json:"title"`
+	// TopN is the length of the largest files and directories tables
+	TopN int `json:"topN"`
+	// MaxDepth is how many levels of the tree the HTML treemap can zoom into
+	MaxDepth int `json:"maxDepth"`
+	// MinShare merges entries smaller than this fraction of the total into
+	// one per directory, which bounds the size of the embedded tree
+	MinShare float64 `json:"minShare"`
+	// CleanupRules produce the cleanup suggestions; nil uses the built-in
+	// rules
+	CleanupRules []*analyzer.CleanupRule `json:"-"`
+}
+
+func DefaultOptions() *Options {
+	return &Options{
+		TopN:     20,
+		MaxDepth: 6,
+		MinShare: 0.001,
+	}
+}
+
+// summary holds what both report formats show.
+type summary struct {
+	Title            string
+	Path             string
+	ScanTime         time.Time
+	GeneratedAt      time.Time
+	TotalSize        int64
+	TotalFiles       int64
+	TotalDirectories int64
+	TopFiles         []*analyzer.RankedNode
+	TopDirectories   []*analyzer.RankedNode
+	Categories       []*models.TypeStat
+	Cleanup          *analyzer.CleanupReport
+}
+
+func summarize(result *models.ScanResult, options *Options) (*summary, *Options, error) {
+	if result == nil || result.Root == nil {
+		return nil, nil, fmt.Errorf("no scan result to report")
+	}
+	defaults := DefaultOptions()
+	if options == nil {
+		options = defaults
+	}
+	resolved := *options
+	if resolved.TopN <= 0 {
+		resolved.TopN = defaults.TopN
+	}
+	if resolved.MaxDepth <= 0 {
+		resolved.MaxDepth = defaults.MaxDepth
+	}
+	if resolved.MinShare <= 0 {
+		resolved.MinShare = defaults.MinShare
+	}
+	if resolved.CleanupRules == nil {
+		resolved.CleanupRules = analyzer.DefaultCleanupRules()
+	}
+	if resolved.Title == "" {
+		resolved.Title = "Disk usage of " + result.Root.Path
+	}
+
+	// One more than needed, as the root is dropped from the directories
+	top := analyzer.TopN(result.Root, &analyzer.TopNOptions{N: resolved.TopN + 1, DirectorySize: analyzer.DirectorySizeCumulative})
+	dirs := make([]*analyzer.RankedNode, 0, resolved.TopN)
+	for _, dir := range top.Directories {
+		if dir.Path != result.Root.Path && len(dirs) < resolved.TopN {
+			dirs = append(dirs, dir)
+		}
+	}
+	files := top.Files
+	if len(files) > resolved.TopN {
+		files = files[:resolved.TopN]
+	}
+
+	s := &summary{
+		Title:            resolved.Title,
+		Path:             result.Root.Path,
+		ScanTime:         result.ScanTime,
+		GeneratedAt:      time.Now(),
+		TotalSize:        result.TotalSize,
+		TotalFiles:       result.TotalFiles,
+		TotalDirectories: result.TotalDirectories,
+		TopFiles:         files,
+		TopDirectories:   dirs,
+		Cleanup:          analyzer.NewCleanupAnalyzer(resolved.CleanupRules, nil).Analyze(result.Root),
+	}
+	if result.FileTypes != nil {
+		s.Categories = result.FileTypes.Categories
+	}
+	return s, &resolved, nil
+}
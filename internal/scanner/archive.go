@@ -0,0 +1,234 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vizdisk/internal/models"
+)
+
+type archiveFormat int
+
+const (
+	archiveNone archiveFormat = iota
+	archiveZip
+	archiveTar
+	archiveTarGz
+)
+
+type archiveEntry struct {
+	name           string
+	size           int64
+	compressedSize int64
+	modTime        time.Time
+	isDir          bool
+}
+
+func detectArchive(name string) archiveFormat {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"), strings.HasSuffix(lower, ".jar"):
+		return archiveZip
+	case strings.HasSuffix(lower, ".tar"):
+		return archiveTar
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return archiveTarGz
+	default:
+		return archiveNone
+	}
+}
+
+// expandArchive reads the listing of an archive file and attaches its entries
+// to node as virtual children. The archive node keeps its on-disk size so
+// directory totals are unaffected.
+func (s *Scanner) expandArchive(node *models.FileNode) error {
+	format := detectArchive(node.Name)
+	if format == archiveNone {
+		return nil
+	}
+
+	f, err := s.fs.Open(node.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var entries []archiveEntry
+	switch format {
+	case archiveZip:
+		entries, err = readZipEntries(f, node.Size)
+	case archiveTar:
+		entries, err = readTarEntries(f)
+	case archiveTarGz:
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		entries, err = readTarEntries(gz)
+		estimateCompressedSizes(entries, node.Size)
+	}
+	if err != nil {
+		return err
+	}
+
+	node.Children = s.buildArchiveTree(node.Path, entries)
+	return nil
+}
+
+func readZipEntries(r io.ReaderAt, size int64) ([]archiveEntry, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]archiveEntry, 0, len(zr.File))
+	for _, file := range zr.File {
+		entries = append(entries, archiveEntry{
+			name:           file.Name,
+			size:           int64(file.UncompressedSize64),
+			compressedSize: int64(file.CompressedSize64),
+			modTime:        file.Modified,
+			isDir:          file.FileInfo().IsDir(),
+		})
+	}
+	return entries, nil
+}
+
+func readTarEntries(r io.Reader) ([]archiveEntry, error) {
+	tr := tar.NewReader(r)
+
+	var entries []archiveEntry
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeDir:
+		default:
+			continue
+		}
+
+		entries = append(entries, archiveEntry{
+			name:           header.Name,
+			size:           header.Size,
+			compressedSize: header.Size,
+			modTime:        header.ModTime,
+			isDir:          header.Typeflag == tar.TypeDir,
+		})
+	}
+}
+
+// estimateCompressedSizes apportions the size of a compressed tarball across
+// its entries in proportion to their uncompressed size, since gzip offers no
+// per-entry boundaries.
+func estimateCompressedSizes(entries []archiveEntry, archiveSize int64) {
+	var total int64
+	for _, entry := range entries {
+		total += entry.size
+	}
+	if total == 0 {
+		return
+	}
+
+	for i := range entries {
+		entries[i].compressedSize = int64(float64(entries[i].size) / float64(total) * float64(archiveSize))
+	}
+}
+
+func (s *Scanner) buildArchiveTree(archivePath string, entries []archiveEntry) []*models.FileNode {
+	root := &models.FileNode{Path: archivePath}
+	dirs := map[string]*models.FileNode{"": root}
+
+	var ensureDir func(dir string) *models.FileNode
+	ensureDir = func(dir string) *models.FileNode {
+		if node, ok := dirs[dir]; ok {
+			return node
+		}
+		parent := ensureDir(parentOf(dir))
+		node := &models.FileNode{
+			ID:        s.generateID(filepath.Join(archivePath, filepath.FromSlash(dir))),
+			Name:      path.Base(dir),
+			Path:      filepath.Join(archivePath, filepath.FromSlash(dir)),
+			Type:      FileTypeDirectory,
+			IsHidden:  s.isHidden(dir),
+			IsVirtual: true,
+			Children:  []*models.FileNode{},
+		}
+		parent.Children = append(parent.Children, node)
+		dirs[dir] = node
+		return node
+	}
+
+	for _, entry := range entries {
+		name := cleanArchivePath(entry.name)
+		if name == "" {
+			continue
+		}
+
+		if entry.isDir {
+			ensureDir(name).LastModified = entry.modTime
+			continue
+		}
+
+		parent := ensureDir(parentOf(name))
+		parent.Children = append(parent.Children, &models.FileNode{
+			ID:             s.generateID(filepath.Join(archivePath, filepath.FromSlash(name))),
+			Name:           path.Base(name),
+			Path:           filepath.Join(archivePath, filepath.FromSlash(name)),
+			Type:           FileTypeFile,
+			Size:           entry.size,
+			CompressedSize: entry.compressedSize,
+			LastModified:   entry.modTime,
+			IsHidden:       s.isHidden(name),
+			IsVirtual:      true,
+		})
+	}
+
+	sumVirtualSizes(root)
+	return root.Children
+}
+
+func sumVirtualSizes(node *models.FileNode) {
+	if node.Type == FileTypeFile {
+		return
+	}
+
+	sort.Slice(node.Children, func(i, j int) bool { return node.Children[i].Name < node.Children[j].Name })
+
+	node.Size, node.CompressedSize = 0, 0
+	for _, child := range node.Children {
+		sumVirtualSizes(child)
+		node.Size += child.Size
+		node.CompressedSize += child.CompressedSize
+	}
+}
+
+// cleanArchivePath normalizes an entry name to a relative slash path that
+// cannot escape the archive.
+func cleanArchivePath(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	return strings.TrimPrefix(name, "/")
+}
+
+func parentOf(name string) string {
+	dir := path.Dir(name)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"vizdisk/internal/vfs"
+)
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+func TestScanner_ExpandArchives(t *testing.T) {
+	m := vfs.NewMemFS()
+	zipData := buildZip(t, map[string]string{
+		"README.md":       "hello world",
+		"src/main.go":     "package main",
+		"../../escape.sh": "x",
+	})
+	tgzData := buildTarGz(t, map[string]string{
+		"a/one.txt": "1111",
+		"a/two.txt": "22",
+	})
+	_ = m.WriteFile("/data/bundle.zip", zipData)
+	_ = m.WriteFile("/data/logs.tar.gz", tgzData)
+	_ = m.WriteFile("/data/broken.jar", []byte("not a zip"))
+
+	options := DefaultScanOptions()
+	options.ExpandArchives = true
+	result, err := NewScannerWithFS(m, options).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+
+	wantTotal := int64(len(zipData) + len(tgzData) + len("not a zip"))
+	if result.TotalSize != wantTotal {
+		t.Errorf("TotalSize = %d, want %d (archive contents must not be double counted)", result.TotalSize, wantTotal)
+	}
+	if result.TotalFiles != 3 {
+		t.Errorf("TotalFiles = %d, want 3", result.TotalFiles)
+	}
+
+	bundle := findChild(result.Root, "bundle.zip")
+	if bundle == nil || bundle.Type != FileTypeFile || bundle.IsVirtual {
+		t.Fatalf("bundle.zip = %+v, want real file node", bundle)
+	}
+	if len(bundle.Children) != 3 {
+		t.Fatalf("bundle.zip children = %d, want 3", len(bundle.Children))
+	}
+	src := findChild(bundle, "src")
+	if src == nil || !src.IsVirtual || src.Type != FileTypeDirectory || src.Size != int64(len("package main")) {
+		t.Errorf("src = %+v, want virtual directory", src)
+	}
+	escape := findChild(bundle, "escape.sh")
+	if escape == nil || escape.Path != "/data/bundle.zip/escape.sh" {
+		t.Errorf("escape.sh = %+v, want path kept inside archive", escape)
+	}
+	if readme := findChild(bundle, "README.md"); readme == nil || readme.CompressedSize == 0 {
+		t.Errorf("README.md = %+v, want compressed size", readme)
+	}
+
+	logs := findChild(result.Root, "logs.tar.gz")
+	a := findChild(logs, "a")
+	if a == nil || a.Size != 6 || len(a.Children) != 2 {
+		t.Errorf("a = %+v, want 2 children totalling 6 bytes", a)
+	}
+
+	if broken := findChild(result.Root, "broken.jar"); broken == nil || len(broken.Children) != 0 {
+		t.Errorf("broken.jar = %+v, want plain file", broken)
+	}
+}
+
+func TestScanner_ArchivesNotExpandedByDefault(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/bundle.zip", buildZip(t, map[string]string{"a": "b"}))
+
+	result, err := NewScannerWithFS(m, nil).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	if bundle := findChild(result.Root, "bundle.zip"); len(bundle.Children) != 0 {
+		t.Errorf("bundle.zip children = %d, want 0", len(bundle.Children))
+	}
+}
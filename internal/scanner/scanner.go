@@ -2,21 +2,24 @@ package scanner
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"vizdisk/internal/models"
+	"vizdisk/internal/vfs"
 )
 
 const (
-	FileTypeFile      = "file"
-	FileTypeDirectory = "directory"
+	FileTypeFile      = models.TypeFile
+	FileTypeDirectory = models.TypeDirectory
 )
 
 type Scanner struct {
+	fs      vfs.FS
 	options *ScanOptions
 	stop    chan bool
 }
@@ -28,6 +31,7 @@ type ScanOptions struct {
 	RespectGitignore bool     `json:"respectGitignore"`
 	MaxDepth         int      `json:"maxDepth"`
 	MaxFileSize      int64    `json:"maxFileSize"`
+	ExpandArchives   bool     `json:"expandArchives"`
 }
 
 func DefaultScanOptions() *ScanOptions {
@@ -38,15 +42,23 @@ func DefaultScanOptions() *ScanOptions {
 		RespectGitignore: true,
 		MaxDepth:         50,
 		MaxFileSize:      1024 * 1024 * 1024, // 1GB
+		ExpandArchives:   false,
 	}
 }
 
 func NewScanner(options *ScanOptions) *Scanner {
+	return NewScannerWithFS(vfs.NewOSFS(), options)
+}
+
+// NewScannerWithFS creates a scanner that walks fsys instead of the OS
+// filesystem.
+func NewScannerWithFS(fsys vfs.FS, options *ScanOptions) *Scanner {
 	if options == nil {
 		options = DefaultScanOptions()
 	}
 
 	return &Scanner{
+		fs:      fsys,
 		options: options,
 		stop:    make(chan bool, 1),
 	}
@@ -56,7 +68,7 @@ func (s *Scanner) ScanPath(rootPath string, progressCallback func(*models.ScanPr
 	startTime := time.Now()
 
 	rootPath = filepath.Clean(rootPath)
-	if _, err := os.Stat(rootPath); os.IsNotExist(err) {
+	if _, err := s.fs.Stat(rootPath); errors.Is(err, fs.ErrNotExist) {
 		return nil, fmt.Errorf("path does not exist: %s", rootPath)
 	}
 
@@ -68,7 +80,7 @@ func (s *Scanner) ScanPath(rootPath string, progressCallback func(*models.ScanPr
 		CurrentPath: rootPath,
 	}
 
-	rootNode, err := s.scanDirectory(rootPath, 0, progress, progressCallback)
+	rootNode, err := s.scanDirectory(rootPath, 0, nil, progress, progressCallback)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +99,7 @@ func (s *Scanner) ScanPath(rootPath string, progressCallback func(*models.ScanPr
 	return result, nil
 }
 
-func (s *Scanner) scanDirectory(dirPath string, depth int, progress *models.ScanProgress, progressCallback func(*models.ScanProgress)) (*models.FileNode, error) {
+func (s *Scanner) scanDirectory(dirPath string, depth int, ancestors []fs.FileInfo, progress *models.ScanProgress, progressCallback func(*models.ScanProgress)) (*models.FileNode, error) {
 	select {
 	case <-s.stop:
 		return nil, fmt.Errorf("scan canceled")
@@ -98,10 +110,11 @@ func (s *Scanner) scanDirectory(dirPath string, depth int, progress *models.Scan
 		return nil, nil
 	}
 
-	fileInfo, err := os.Stat(dirPath)
+	fileInfo, err := s.fs.Stat(dirPath)
 	if err != nil {
 		return nil, err
 	}
+	ancestors = append(ancestors, fileInfo)
 
 	node := &models.FileNode{
 		ID:           s.generateID(dirPath),
@@ -113,6 +126,7 @@ func (s *Scanner) scanDirectory(dirPath string, depth int, progress *models.Scan
 		Permissions:  fileInfo.Mode().String(),
 		Children:     []*models.FileNode{},
 	}
+	s.applyMetadata(node, fileInfo)
 
 	progress.CurrentPath = dirPath
 	progress.DirectoriesScanned++
@@ -120,7 +134,7 @@ func (s *Scanner) scanDirectory(dirPath string, depth int, progress *models.Scan
 		progressCallback(progress)
 	}
 
-	entries, err := os.ReadDir(dirPath)
+	entries, err := s.fs.ReadDir(dirPath)
 	if err != nil {
 		return node, nil
 	}
@@ -133,8 +147,8 @@ func (s *Scanner) scanDirectory(dirPath string, depth int, progress *models.Scan
 			continue
 		}
 
-		if entry.IsDir() {
-			childNode, err := s.scanDirectory(entryPath, depth+1, progress, progressCallback)
+		if entry.IsDir() || s.isFollowableDirLink(entryPath, entry, ancestors) {
+			childNode, err := s.scanDirectory(entryPath, depth+1, ancestors, progress, progressCallback)
 			if err != nil {
 				continue
 			}
@@ -154,13 +168,20 @@ func (s *Scanner) scanDirectory(dirPath string, depth int, progress *models.Scan
 
 	node.Children = children
 	node.Size = s.calculateDirectorySize(node)
+	node.AllocatedSize = 0
+	for _, child := range children {
+		node.AllocatedSize += child.AllocatedSize
+	}
 
 	return node, nil
 }
 
 func (s *Scanner) scanFile(filePath string, progress *models.ScanProgress, progressCallback func(*models.ScanProgress)) (*models.FileNode, error) {
-	fileInfo, err := os.Stat(filePath)
+	fileInfo, err := s.fs.Stat(filePath)
 	if err != nil {
+		if node := s.brokenLink(filePath); node != nil {
+			return node, nil
+		}
 		return nil, err
 	}
 
@@ -178,6 +199,12 @@ func (s *Scanner) scanFile(filePath string, progress *models.ScanProgress, progr
 		IsHidden:     s.isHidden(filePath),
 		Permissions:  fileInfo.Mode().String(),
 	}
+	s.applyMetadata(node, fileInfo)
+
+	if s.options.ExpandArchives && fileInfo.Mode().IsRegular() {
+		// Unreadable or corrupt archives are shown as plain files
+		_ = s.expandArchive(node)
+	}
 
 	progress.FilesScanned++
 	progress.TotalSizeScanned += fileInfo.Size()
@@ -188,6 +215,61 @@ func (s *Scanner) scanFile(filePath string, progress *models.ScanProgress, progr
 	return node, nil
 }
 
+// brokenLink returns a node for filePath if it is a symlink that cannot be
+// resolved, so dangling links stay visible instead of being skipped like
+// other unreadable entries.
+func (s *Scanner) brokenLink(filePath string) *models.FileNode {
+	linkInfo, err := s.fs.Lstat(filePath)
+	if err != nil || linkInfo.Mode()&fs.ModeSymlink == 0 {
+		return nil
+	}
+	target, _ := s.fs.Readlink(filePath)
+
+	node := &models.FileNode{
+		ID:           s.generateID(filePath),
+		Name:         filepath.Base(filePath),
+		Path:         filePath,
+		Type:         FileTypeFile,
+		LastModified: linkInfo.ModTime(),
+		IsHidden:     s.isHidden(filePath),
+		Permissions:  linkInfo.Mode().String(),
+		IsBrokenLink: true,
+		LinkTarget:   target,
+	}
+	s.applyMetadata(node, linkInfo)
+	return node
+}
+
+// applyMetadata copies platform specific attributes onto node.
+func (s *Scanner) applyMetadata(node *models.FileNode, fileInfo fs.FileInfo) {
+	md := vfs.MetadataOf(fileInfo)
+	node.LastAccessed = md.AccessTime
+	node.Owner = md.Owner
+	node.Group = md.Group
+	node.AllocatedSize = md.Allocated
+}
+
+// isFollowableDirLink reports whether entry is a symlink to a directory that
+// should be descended into. Links pointing back at a directory already on the
+// current path are refused so that symlink loops terminate.
+func (s *Scanner) isFollowableDirLink(path string, entry fs.DirEntry, ancestors []fs.FileInfo) bool {
+	if !s.options.FollowSymlinks || entry.Type()&fs.ModeSymlink == 0 {
+		return false
+	}
+
+	info, err := s.fs.Stat(path)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+
+	for _, ancestor := range ancestors {
+		if s.fs.SameFile(ancestor, info) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Scanner) shouldExclude(path, name string) bool {
 	if !s.options.ShowHiddenFiles && s.isHidden(path) {
 		return true
@@ -208,10 +290,28 @@ func (s *Scanner) isHidden(path string) bool {
 }
 
 func (s *Scanner) generateID(path string) string {
+	return GenerateID(path)
+}
+
+// GenerateID returns the stable node ID for a path.
+func GenerateID(path string) string {
 	hash := sha256.Sum256([]byte(path))
 	return fmt.Sprintf("%x", hash)
 }
 
+// NewResult wraps a tree that was built without a filesystem scan, such as
+// an imported or synthesized one, in a ScanResult with computed totals.
+func NewResult(root *models.FileNode, scanTime time.Time) *models.ScanResult {
+	var s Scanner
+	result := &models.ScanResult{
+		Root:     root,
+		ScanTime: scanTime,
+	}
+	result.TotalSize = s.calculateTotalSize(root)
+	result.TotalFiles, result.TotalDirectories = s.calculateCounts(root)
+	return result
+}
+
 func (s *Scanner) calculateTotalSize(node *models.FileNode) int64 {
 	if node == nil {
 		return 0
@@ -254,6 +354,11 @@ func (s *Scanner) calculateCounts(node *models.FileNode) (files, directories int
 	return files, directories
 }
 
+// Options returns the options scans are made with.
+func (s *Scanner) Options() *ScanOptions {
+	return s.options
+}
+
 func (s *Scanner) Stop() {
 	select {
 	case s.stop <- true:
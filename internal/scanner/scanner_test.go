@@ -0,0 +1,166 @@
+package scanner
+
+import (
+	"io/fs"
+	"testing"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/vfs"
+)
+
+func findChild(node *models.FileNode, name string) *models.FileNode {
+	for _, child := range node.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func TestScanner_ScanPath(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/a.txt", make([]byte, 100))
+	_ = m.WriteFile("/data/sub/b.bin", make([]byte, 250))
+	_ = m.WriteFile("/data/sub/c.tmp", make([]byte, 10))
+	_ = m.WriteFile("/data/.hidden", make([]byte, 5))
+
+	s := NewScannerWithFS(m, DefaultScanOptions())
+	result, err := s.ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+
+	if result.TotalSize != 350 {
+		t.Errorf("TotalSize = %d, want 350", result.TotalSize)
+	}
+	if result.TotalFiles != 2 || result.TotalDirectories != 2 {
+		t.Errorf("counts = %d files, %d dirs, want 2, 2", result.TotalFiles, result.TotalDirectories)
+	}
+	if sub := findChild(result.Root, "sub"); sub == nil || sub.Size != 250 {
+		t.Errorf("sub = %+v, want size 250", sub)
+	}
+}
+
+func TestScanner_ScanPathNotExist(t *testing.T) {
+	s := NewScannerWithFS(vfs.NewMemFS(), nil)
+	if _, err := s.ScanPath("/missing", nil); err == nil {
+		t.Error("ScanPath() should error on missing path")
+	}
+}
+
+func TestScanner_Errors(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/keep.txt", make([]byte, 10))
+	_ = m.WriteFile("/data/vanished.txt", make([]byte, 20))
+	_ = m.WriteFile("/data/locked/secret.txt", make([]byte, 30))
+	m.FailStat("/data/vanished.txt", fs.ErrNotExist)
+	m.FailReadDir("/data/locked", fs.ErrPermission)
+
+	result, err := NewScannerWithFS(m, nil).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+
+	if findChild(result.Root, "vanished.txt") != nil {
+		t.Error("vanished file should be skipped")
+	}
+	locked := findChild(result.Root, "locked")
+	if locked == nil {
+		t.Fatal("unreadable directory should still be reported")
+	}
+	if len(locked.Children) != 0 || locked.Size != 0 {
+		t.Errorf("locked = %d children, size %d, want empty", len(locked.Children), locked.Size)
+	}
+	if result.TotalSize != 10 {
+		t.Errorf("TotalSize = %d, want 10", result.TotalSize)
+	}
+}
+
+func TestScanner_SymlinkLoop(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/dir/file.txt", make([]byte, 10))
+	_ = m.Symlink("/data", "/data/dir/loop")
+	_ = m.Symlink("dir", "/data/alias")
+
+	options := DefaultScanOptions()
+	options.FollowSymlinks = true
+	result, err := NewScannerWithFS(m, options).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+
+	alias := findChild(result.Root, "alias")
+	if alias == nil || alias.Type != FileTypeDirectory {
+		t.Fatalf("alias = %+v, want followed directory", alias)
+	}
+	if result.TotalSize != 20 {
+		t.Errorf("TotalSize = %d, want 20", result.TotalSize)
+	}
+
+	loop := findChild(findChild(result.Root, "dir"), "loop")
+	if loop == nil || loop.Type != FileTypeFile {
+		t.Errorf("loop = %+v, want unfollowed link", loop)
+	}
+}
+
+func TestScanner_BrokenSymlink(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/file.txt", make([]byte, 10))
+	_ = m.Symlink("/opt/gone/bin/tool", "/data/tool")
+
+	result, err := NewScannerWithFS(m, DefaultScanOptions()).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+
+	tool := findChild(result.Root, "tool")
+	if tool == nil || !tool.IsBrokenLink || tool.LinkTarget != "/opt/gone/bin/tool" {
+		t.Fatalf("tool = %+v, want broken link node", tool)
+	}
+	if tool.Size != 0 || result.TotalSize != 10 {
+		t.Errorf("tool size = %d, total = %d, want 0 and 10", tool.Size, result.TotalSize)
+	}
+}
+
+func TestScanner_AllocatedSize(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/small.txt", make([]byte, 10))
+	_ = m.WriteFile("/data/sub/sparse.img", make([]byte, 100000))
+	_ = m.SetAllocated("/data/small.txt", 4096)
+	_ = m.SetAllocated("/data/sub/sparse.img", 8192)
+
+	result, err := NewScannerWithFS(m, DefaultScanOptions()).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	if sub := findChild(result.Root, "sub"); sub == nil || sub.AllocatedSize != 8192 {
+		t.Errorf("sub = %+v, want 8192 allocated", sub)
+	}
+	if result.Root.AllocatedSize != 4096+8192 {
+		t.Errorf("root allocated = %d, want %d", result.Root.AllocatedSize, 4096+8192)
+	}
+}
+
+func TestScanner_Options(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/small", make([]byte, 10))
+	_ = m.WriteFile("/data/big", make([]byte, 1000))
+	_ = m.WriteFile("/data/.hidden", make([]byte, 5))
+	_ = m.WriteFile("/data/a/b/deep", make([]byte, 7))
+
+	options := &ScanOptions{ShowHiddenFiles: true, MaxDepth: 1, MaxFileSize: 100}
+	result, err := NewScannerWithFS(m, options).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+
+	if findChild(result.Root, "big") != nil {
+		t.Error("file above MaxFileSize should be skipped")
+	}
+	if findChild(result.Root, ".hidden") == nil {
+		t.Error("hidden file should be included with ShowHiddenFiles")
+	}
+	if a := findChild(result.Root, "a"); a == nil || findChild(a, "b") != nil {
+		t.Error("directories beyond MaxDepth should be skipped")
+	}
+}
@@ -0,0 +1,360 @@
+// Package search finds nodes in a scanned tree by name or path and by size,
+// date and type.
+package search
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"vizdisk/internal/models"
+	expression "vizdisk/internal/query"
+)
+
+const (
+	ModeSubstring = "substring"
+	ModeGlob      = "glob"
+	ModeRegex     = "regex"
+
+	FieldName = "name"
+	FieldPath = "path"
+
+	SortTree = "tree"
+	SortSize = "size"
+	SortName = "name"
+
+	defaultLimit = 100
+)
+
+// Query selects nodes. Empty fields do not filter; an empty Pattern matches
+// every node that passes the other predicates. Expression is written in the
+// query language, e.g. "files > 100MB, not touched in 1 year".
+type Query struct {
+	Pattern        string    `json:"pattern"`
+	Mode           string    `json:"mode"`
+	Field          string    `json:"field"`
+	CaseSensitive  bool      `json:"caseSensitive"`
+	Type           string    `json:"type"`
+	Extensions     []string  `json:"extensions"`
+	MinSize        int64     `json:"minSize"`
+	MaxSize        int64     `json:"maxSize"`
+	ModifiedAfter  time.Time `json:"modifiedAfter"`
+	ModifiedBefore time.Time `json:"modifiedBefore"`
+	Expression     string    `json:"expression"`
+	SortBy         string    `json:"sortBy"`
+	Offset         int       `json:"offset"`
+	Limit          int       `json:"limit"`
+}
+
+// Ancestor identifies one directory on the way from the root to a hit.
+type Ancestor struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Hit describes a matching node without its children, so that a page of
+// results stays small. Ancestors run from the root down to the parent.
+type Hit struct {
+	ID           string      `json:"id"`
+	Name         string      `json:"name"`
+	Path         string      `json:"path"`
+	Size         int64       `json:"size"`
+	Type         string      `json:"type"`
+	LastModified time.Time   `json:"lastModified"`
+	IsVirtual    bool        `json:"isVirtual,omitempty"`
+	Ancestors    []*Ancestor `json:"ancestors"`
+}
+
+type Results struct {
+	Hits   []*Hit `json:"hits"`
+	Total  int    `json:"total"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+type entry struct {
+	node   *models.FileNode
+	parent int
+	name   string
+	path   string
+	ext    string
+}
+
+// Index is a flattened, depth-first copy of a tree with lower-cased names and
+// paths precomputed. Lookups by extension, type and size narrow a search to
+// the entries that can match before the remaining conditions are checked on
+// each of them; searches by pattern or expression alone check every entry.
+// It is safe for concurrent searches.
+type Index struct {
+	entries []entry
+	// byExtension and byType hold entry positions in tree order, bySize
+	// holds them by ascending size
+	byExtension map[string][]int
+	byType      map[string][]int
+	bySize      []int
+}
+
+func NewIndex(root *models.FileNode) *Index {
+	idx := &Index{byExtension: make(map[string][]int), byType: make(map[string][]int)}
+	if root == nil {
+		return idx
+	}
+
+	var add func(node *models.FileNode, parent int)
+	add = func(node *models.FileNode, parent int) {
+		idx.entries = append(idx.entries, entry{
+			node:   node,
+			parent: parent,
+			name:   strings.ToLower(node.Name),
+			path:   strings.ToLower(node.Path),
+			ext:    models.Extension(node.Name),
+		})
+		self := len(idx.entries) - 1
+		for _, child := range node.Children {
+			add(child, self)
+		}
+	}
+	add(root, -1)
+
+	idx.bySize = make([]int, len(idx.entries))
+	for i, e := range idx.entries {
+		idx.byExtension[e.ext] = append(idx.byExtension[e.ext], i)
+		idx.byType[e.node.Type] = append(idx.byType[e.node.Type], i)
+		idx.bySize[i] = i
+	}
+	sort.SliceStable(idx.bySize, func(a, b int) bool {
+		return idx.entries[idx.bySize[a]].node.Size < idx.entries[idx.bySize[b]].node.Size
+	})
+	return idx
+}
+
+// Len returns the number of indexed nodes.
+func (idx *Index) Len() int {
+	return len(idx.entries)
+}
+
+// Search returns one page of the nodes matching query.
+func (idx *Index) Search(query *Query) (*Results, error) {
+	match, err := compile(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []int
+	if positions, ok := idx.candidates(query); ok {
+		for _, i := range positions {
+			if match(&idx.entries[i]) {
+				matched = append(matched, i)
+			}
+		}
+	} else {
+		for i := range idx.entries {
+			if match(&idx.entries[i]) {
+				matched = append(matched, i)
+			}
+		}
+	}
+
+	switch query.SortBy {
+	case "", SortTree:
+	case SortSize:
+		sort.SliceStable(matched, func(a, b int) bool {
+			return idx.entries[matched[a]].node.Size > idx.entries[matched[b]].node.Size
+		})
+	case SortName:
+		sort.SliceStable(matched, func(a, b int) bool {
+			return idx.entries[matched[a]].name < idx.entries[matched[b]].name
+		})
+	default:
+		return nil, fmt.Errorf("unknown sort order: %s", query.SortBy)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	offset := max(query.Offset, 0)
+	results := &Results{Hits: []*Hit{}, Total: len(matched), Offset: offset, Limit: limit}
+	for i := offset; i < len(matched) && i < offset+limit; i++ {
+		results.Hits = append(results.Hits, idx.hit(matched[i]))
+	}
+	return results, nil
+}
+
+// candidates returns the positions, in tree order, of the entries that the
+// extension, type or size conditions of query allow, taking whichever
+// condition leaves the fewest. ok is false when query has none of them.
+func (idx *Index) candidates(query *Query) (positions []int, ok bool) {
+	if len(query.Extensions) > 0 {
+		seen := make(map[string]bool, len(query.Extensions))
+		positions = []int{}
+		for _, ext := range query.Extensions {
+			ext = normalizeExtension(ext)
+			if !seen[ext] {
+				seen[ext] = true
+				positions = append(positions, idx.byExtension[ext]...)
+			}
+		}
+		sort.Ints(positions)
+		ok = true
+	}
+	if query.Type != "" && (!ok || len(idx.byType[query.Type]) < len(positions)) {
+		positions, ok = idx.byType[query.Type], true
+	}
+	if query.MinSize > 0 || query.MaxSize > 0 {
+		size := func(i int) int64 { return idx.entries[idx.bySize[i]].node.Size }
+		lo := sort.Search(len(idx.bySize), func(i int) bool { return size(i) >= query.MinSize })
+		hi := len(idx.bySize)
+		if query.MaxSize > 0 {
+			hi = max(sort.Search(len(idx.bySize), func(i int) bool { return size(i) > query.MaxSize }), lo)
+		}
+		if !ok || hi-lo < len(positions) {
+			positions, ok = append([]int{}, idx.bySize[lo:hi]...), true
+			sort.Ints(positions)
+		}
+	}
+	return positions, ok
+}
+
+func (idx *Index) hit(i int) *Hit {
+	node := idx.entries[i].node
+	var ancestors []*Ancestor
+	for p := idx.entries[i].parent; p >= 0; p = idx.entries[p].parent {
+		parent := idx.entries[p].node
+		ancestors = append(ancestors, &Ancestor{ID: parent.ID, Name: parent.Name, Path: parent.Path})
+	}
+	for l, r := 0, len(ancestors)-1; l < r; l, r = l+1, r-1 {
+		ancestors[l], ancestors[r] = ancestors[r], ancestors[l]
+	}
+	if ancestors == nil {
+		ancestors = []*Ancestor{}
+	}
+
+	return &Hit{
+		ID:           node.ID,
+		Name:         node.Name,
+		Path:         node.Path,
+		Size:         node.Size,
+		Type:         node.Type,
+		LastModified: node.LastModified,
+		IsVirtual:    node.IsVirtual,
+		Ancestors:    ancestors,
+	}
+}
+
+// compile turns query into a predicate, validating the pattern once up front.
+func compile(query *Query) (func(*entry) bool, error) {
+	text, err := compilePattern(query)
+	if err != nil {
+		return nil, err
+	}
+	var where expression.Predicate
+	if query.Expression != "" {
+		if where, err = expression.Compile(query.Expression, nil); err != nil {
+			return nil, fmt.Errorf("invalid expression: %w", err)
+		}
+	}
+
+	extensions := make(map[string]bool, len(query.Extensions))
+	for _, ext := range query.Extensions {
+		extensions[normalizeExtension(ext)] = true
+	}
+
+	return func(e *entry) bool {
+		node := e.node
+		if query.Type != "" && node.Type != query.Type {
+			return false
+		}
+		if len(extensions) > 0 && !extensions[e.ext] {
+			return false
+		}
+		if query.MinSize > 0 && node.Size < query.MinSize {
+			return false
+		}
+		if query.MaxSize > 0 && node.Size > query.MaxSize {
+			return false
+		}
+		if !query.ModifiedAfter.IsZero() && !node.LastModified.After(query.ModifiedAfter) {
+			return false
+		}
+		if !query.ModifiedBefore.IsZero() && !node.LastModified.Before(query.ModifiedBefore) {
+			return false
+		}
+		if where != nil && !where(node) {
+			return false
+		}
+		return text == nil || text(e)
+	}, nil
+}
+
+// normalizeExtension spells ext the way models.Extension returns it.
+func normalizeExtension(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+func compilePattern(query *Query) (func(*entry) bool, error) {
+	if query.Pattern == "" {
+		return nil, nil
+	}
+
+	var field func(*entry) string
+	switch query.Field {
+	case "", FieldName:
+		field = func(e *entry) string {
+			if query.CaseSensitive {
+				return e.node.Name
+			}
+			return e.name
+		}
+	case FieldPath:
+		field = func(e *entry) string {
+			if query.CaseSensitive {
+				return e.node.Path
+			}
+			return e.path
+		}
+	default:
+		return nil, fmt.Errorf("unknown search field: %s", query.Field)
+	}
+
+	pattern := query.Pattern
+	switch query.Mode {
+	case "", ModeSubstring:
+		if !query.CaseSensitive {
+			pattern = strings.ToLower(pattern)
+		}
+		return func(e *entry) bool { return strings.Contains(field(e), pattern) }, nil
+	case ModeGlob:
+		if !query.CaseSensitive {
+			pattern = strings.ToLower(pattern)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", query.Pattern, err)
+		}
+		return func(e *entry) bool {
+			ok, _ := filepath.Match(pattern, field(e))
+			return ok
+		}, nil
+	case ModeRegex:
+		if !query.CaseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		// Match the original spelling; (?i) handles case folding
+		return func(e *entry) bool {
+			if query.Field == FieldPath {
+				return re.MatchString(e.node.Path)
+			}
+			return re.MatchString(e.node.Name)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown search mode: %s", query.Mode)
+	}
+}
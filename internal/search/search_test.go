@@ -0,0 +1,116 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+func newTestIndex(t *testing.T) *Index {
+	t.Helper()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := vfs.NewMemFS()
+	files := map[string]int{
+		"/data/Photos/IMG_001.JPG":      500,
+		"/data/Photos/img_002.jpg":      300,
+		"/data/Photos/trip/video.mp4":   4000,
+		"/data/src/main.go":             20,
+		"/data/src/main_test.go":        30,
+		"/data/src/vendor/lib/image.go": 100,
+	}
+	for path, size := range files {
+		_ = m.WriteFile(path, make([]byte, size))
+		_ = m.Chtimes(path, now)
+	}
+	_ = m.Chtimes("/data/Photos/IMG_001.JPG", now.AddDate(-3, 0, 0))
+
+	result, err := scanner.NewScannerWithFS(m, scanner.DefaultScanOptions()).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	return NewIndex(result.Root)
+}
+
+func hitPaths(results *Results) []string {
+	paths := make([]string, len(results.Hits))
+	for i, hit := range results.Hits {
+		paths[i] = hit.Path
+	}
+	return paths
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := newTestIndex(t)
+	if idx.Len() != 12 {
+		t.Fatalf("Len() = %d, want 12", idx.Len())
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"substring ignores case", Query{Pattern: "img"}, []string{"/data/Photos/IMG_001.JPG", "/data/Photos/img_002.jpg"}},
+		{"case sensitive", Query{Pattern: "img", CaseSensitive: true}, []string{"/data/Photos/img_002.jpg"}},
+		{"glob", Query{Pattern: "*_test.go", Mode: ModeGlob}, []string{"/data/src/main_test.go"}},
+		{"regex on path", Query{Pattern: `/vendor/.*\.go$`, Mode: ModeRegex, Field: FieldPath}, []string{"/data/src/vendor/lib/image.go"}},
+		{"type and size", Query{Type: scanner.FileTypeFile, MinSize: 300, MaxSize: 1000}, []string{"/data/Photos/IMG_001.JPG", "/data/Photos/img_002.jpg"}},
+		{"extension", Query{Extensions: []string{".jpg"}, ModifiedAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, []string{"/data/Photos/img_002.jpg"}},
+		{"expression", Query{Expression: "files > 1KB, ext = mp4"}, []string{"/data/Photos/trip/video.mp4"}},
+		{"extensions in tree order", Query{Extensions: []string{"JPG", ".mp4", "jpg"}}, []string{"/data/Photos/IMG_001.JPG", "/data/Photos/img_002.jpg", "/data/Photos/trip/video.mp4"}},
+		{"size range", Query{MinSize: 30, MaxSize: 300}, []string{"/data/Photos/img_002.jpg", "/data/src", "/data/src/main_test.go", "/data/src/vendor", "/data/src/vendor/lib", "/data/src/vendor/lib/image.go"}},
+		{"empty size range", Query{MinSize: 400, MaxSize: 300}, nil},
+		{"directories by size", Query{Type: scanner.FileTypeDirectory, SortBy: SortSize, Limit: 2}, []string{"/data", "/data/Photos"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := idx.Search(&tt.query)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			got := hitPaths(results)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Search() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestIndex_SearchPaging(t *testing.T) {
+	idx := newTestIndex(t)
+
+	results, err := idx.Search(&Query{Type: scanner.FileTypeFile, Offset: 4, Limit: 4})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if results.Total != 6 || len(results.Hits) != 2 {
+		t.Fatalf("Total = %d, hits = %d, want 6 and 2", results.Total, len(results.Hits))
+	}
+
+	hit := results.Hits[1]
+	if hit.Path != "/data/src/vendor/lib/image.go" {
+		t.Fatalf("last hit = %s", hit.Path)
+	}
+	var chain []string
+	for _, a := range hit.Ancestors {
+		chain = append(chain, a.Path)
+	}
+	want := []string{"/data", "/data/src", "/data/src/vendor", "/data/src/vendor/lib"}
+	if len(chain) != len(want) || chain[0] != want[0] || chain[3] != want[3] {
+		t.Errorf("Ancestors = %v, want %v", chain, want)
+	}
+
+	for _, query := range []Query{{Pattern: "[", Mode: ModeGlob}, {Pattern: "(", Mode: ModeRegex}, {Pattern: "x", Mode: "fuzzy"}, {Expression: "size >"}} {
+		if _, err := idx.Search(&query); err == nil {
+			t.Errorf("Search(%+v) succeeded, want error", query)
+		}
+	}
+}
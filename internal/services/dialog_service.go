@@ -53,6 +53,27 @@ func (s *DialogService) OpenFileDialog(filters []runtime.FileFilter) (string, er
 	return result, nil
 }
 
+// SaveFileDialog asks where to save a file and returns an empty path if the
+// user cancels.
+func (s *DialogService) SaveFileDialog(defaultFilename string, filters []runtime.FileFilter) (string, error) {
+	if s.ctx == nil {
+		return "", nil
+	}
+
+	options := runtime.SaveDialogOptions{
+		Title:           "Save File",
+		DefaultFilename: defaultFilename,
+		Filters:         filters,
+	}
+
+	result, err := runtime.SaveFileDialog(s.ctx, options)
+	if err != nil {
+		return "", err
+	}
+
+	return result, nil
+}
+
 func (s *DialogService) ShowMessageDialog(title, message string) {
 	if s.ctx == nil {
 		return
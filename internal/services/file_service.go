@@ -54,7 +54,8 @@ func (s *FileService) DeletePath(path string) error {
 		return fmt.Errorf("cannot delete system directory")
 	}
 
-	if _, err := os.Stat(path); err != nil {
+	// Lstat so that dangling symlinks can be deleted too
+	if _, err := os.Lstat(path); err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("path does not exist: %s", path)
 		}
@@ -88,3 +89,107 @@ func (s *FileService) IsDirectory(path string) bool {
 	}
 	return info.IsDir()
 }
+
+// RemovalSkip records a path that RemoveEmpty left in place.
+type RemovalSkip struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type RemovalResult struct {
+	DryRun  bool           `json:"dryRun"`
+	Removed []string       `json:"removed"`
+	Skipped []*RemovalSkip `json:"skipped"`
+}
+
+// RemoveEmpty deletes zero-byte files and directories containing nothing but
+// other empty directories. Every path is checked on disk first, since the
+// scanned tree may be out of date or have skipped entries; anything that is
+// no longer empty is skipped. With dryRun nothing is deleted and Removed lists
+// what would have been.
+func (s *FileService) RemoveEmpty(paths []string, dryRun bool) *RemovalResult {
+	result := &RemovalResult{
+		DryRun:  dryRun,
+		Removed: []string{},
+		Skipped: []*RemovalSkip{},
+	}
+	homeDir, _ := os.UserHomeDir()
+
+	for _, path := range paths {
+		skip := func(reason string) {
+			result.Skipped = append(result.Skipped, &RemovalSkip{Path: path, Reason: reason})
+		}
+
+		absPath, _ := filepath.Abs(path)
+		if path == "" || absPath == "/" || absPath == homeDir {
+			skip("cannot delete system directory")
+			continue
+		}
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			skip(err.Error())
+			continue
+		}
+
+		switch {
+		case info.IsDir():
+			if err := checkEmptyDir(path); err != nil {
+				skip(err.Error())
+				continue
+			}
+			if !dryRun {
+				if err := removeEmptyDir(path); err != nil {
+					skip(err.Error())
+					continue
+				}
+			}
+		case info.Mode().IsRegular() && info.Size() == 0:
+			if !dryRun {
+				if err := os.Remove(path); err != nil {
+					skip(err.Error())
+					continue
+				}
+			}
+		default:
+			skip("not an empty file or directory")
+			continue
+		}
+		result.Removed = append(result.Removed, path)
+	}
+
+	return result
+}
+
+func checkEmptyDir(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			return fmt.Errorf("directory is not empty: %s", filepath.Join(path, entry.Name()))
+		}
+		if err := checkEmptyDir(filepath.Join(path, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEmptyDir removes path bottom-up with os.Remove, which refuses to
+// delete a directory that gained an entry since it was checked.
+func removeEmptyDir(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if err := removeEmptyDir(filepath.Join(path, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Remove(path)
+}
@@ -86,6 +86,15 @@ func TestFileService_DeletePath(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "delete dangling symlink",
+			setup: func() string {
+				linkPath := filepath.Join(tempDir, "dangling")
+				_ = os.Symlink(filepath.Join(tempDir, "missing"), linkPath)
+				return linkPath
+			},
+			wantErr: false,
+		},
 		{
 			name: "delete directory",
 			setup: func() string {
@@ -113,7 +122,7 @@ func TestFileService_DeletePath(t *testing.T) {
 			}
 
 			if !tt.wantErr && path != "" {
-				if _, err := os.Stat(path); !os.IsNotExist(err) {
+				if _, err := os.Lstat(path); !os.IsNotExist(err) {
 					t.Errorf("DeletePath() path still exists after deletion")
 				}
 			}
@@ -236,3 +245,39 @@ func TestFileService_IsDirectory(t *testing.T) {
 		})
 	}
 }
+
+func TestFileService_RemoveEmpty(t *testing.T) {
+	service := NewFileService()
+	tempDir := t.TempDir()
+
+	emptyTree := filepath.Join(tempDir, "empty", "a", "b")
+	_ = os.MkdirAll(emptyTree, 0o755)
+	grown := filepath.Join(tempDir, "grown")
+	_ = os.MkdirAll(grown, 0o755)
+	_ = os.WriteFile(filepath.Join(grown, "new.txt"), []byte("x"), 0o644)
+	zero := filepath.Join(tempDir, "zero.txt")
+	_ = os.WriteFile(zero, nil, 0o644)
+	full := filepath.Join(tempDir, "full.txt")
+	_ = os.WriteFile(full, []byte("data"), 0o644)
+
+	paths := []string{filepath.Join(tempDir, "empty"), grown, zero, full}
+
+	dry := service.RemoveEmpty(paths, true)
+	if !dry.DryRun || len(dry.Removed) != 2 || len(dry.Skipped) != 2 {
+		t.Fatalf("dry run = %+v, want 2 removable and 2 skipped", dry)
+	}
+	if !service.PathExists(emptyTree) || !service.PathExists(zero) {
+		t.Fatal("dry run deleted files")
+	}
+
+	result := service.RemoveEmpty(paths, false)
+	if len(result.Removed) != 2 {
+		t.Errorf("Removed = %v, want 2 paths", result.Removed)
+	}
+	if service.PathExists(filepath.Join(tempDir, "empty")) || service.PathExists(zero) {
+		t.Error("empty paths still exist")
+	}
+	if !service.PathExists(grown) || !service.PathExists(full) {
+		t.Error("non-empty paths were removed")
+	}
+}
@@ -99,3 +99,12 @@ func (s *PlatformService) OpenInFileManager(path string) error {
 func (s *PlatformService) IsPlatform(os string) bool {
 	return runtime.GOOS == os
 }
+
+// GetConfigDirectory returns the per-user directory for vizdisk settings
+func (s *PlatformService) GetConfigDirectory() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "vizdisk"), nil
+}
@@ -0,0 +1,453 @@
+// Package snapshot persists scan results so they survive restarts and can be
+// compared later.
+//
+// A snapshot file is a gzip stream holding the magic "VZSNAP", the format
+// version as a uvarint, a length-prefixed JSON header and then the tree in
+// pre-order. Each node record stores only its name; paths and IDs are
+// rebuilt from the parent on load unless they differ from what the scanner
+// would produce. Permissions, owners and groups go through a string table
+// since a handful of values repeat across the whole tree. Nodes are written
+// as the tree is walked, so encoding never holds a second copy of it.
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+// Version is the format version written by this package. Files with a
+// newer version are rejected.
+const Version = 1
+
+const (
+	magic = "VZSNAP"
+
+	// Limits that keep a corrupt file from triggering huge allocations
+	maxStringLen = 1 << 20
+	maxHeaderLen = 16 << 20
+	maxPrealloc  = 1024
+	maxTreeDepth = 4096
+)
+
+const (
+	flagDirectory = 1 << iota
+	flagHidden
+	flagVirtual
+	flagBrokenLink
+	flagPath
+	flagID
+	flagModified
+	flagAccessed
+	flagAllocated
+	// A file with children: an archive expanded into virtual entries
+	flagChildren
+)
+
+// Info describes a snapshot without its tree. ID and FileSize come from the
+// file in the store and are not part of the encoded header.
+type Info struct {
+	ID               string               `json:"id"`
+	Label            string               `json:"label"`
+	Path             string               `json:"path"`
+	CreatedAt        time.Time            `json:"createdAt"`
+	ScanTime         time.Time            `json:"scanTime"`
+	TotalSize        int64                `json:"totalSize"`
+	TotalFiles       int64                `json:"totalFiles"`
+	TotalDirectories int64                `json:"totalDirectories"`
+	Options          *scanner.ScanOptions `json:"options,omitempty"`
+	FileSize         int64                `json:"fileSize"`
+}
+
+type Snapshot struct {
+	Info   *Info              `json:"info"`
+	Result *models.ScanResult `json:"result"`
+}
+
+type header struct {
+	Label            string                `json:"label,omitempty"`
+	Path             string                `json:"path"`
+	CreatedAt        time.Time             `json:"createdAt"`
+	ScanTime         time.Time             `json:"scanTime"`
+	ScanDurationMs   int64                 `json:"scanDuration"`
+	TotalSize        int64                 `json:"totalSize"`
+	TotalFiles       int64                 `json:"totalFiles"`
+	TotalDirectories int64                 `json:"totalDirectories"`
+	Options          *scanner.ScanOptions  `json:"options,omitempty"`
+	FileTypes        *models.TypeBreakdown `json:"fileTypes,omitempty"`
+}
+
+// Write encodes result as a snapshot. options records how the scan was made
+// and may be nil.
+func Write(w io.Writer, result *models.ScanResult, options *scanner.ScanOptions, label string) error {
+	if result == nil || result.Root == nil {
+		return fmt.Errorf("no scan result to save")
+	}
+
+	zw := gzip.NewWriter(w)
+	e := &encoder{w: bufio.NewWriter(zw), strings: map[string]uint64{"": 0}}
+
+	headerJSON, err := json.Marshal(&header{
+		Label:            label,
+		Path:             result.Root.Path,
+		CreatedAt:        time.Now(),
+		ScanTime:         result.ScanTime,
+		ScanDurationMs:   result.ScanDurationMs,
+		TotalSize:        result.TotalSize,
+		TotalFiles:       result.TotalFiles,
+		TotalDirectories: result.TotalDirectories,
+		Options:          options,
+		FileTypes:        result.FileTypes,
+	})
+	if err != nil {
+		return err
+	}
+	e.w.WriteString(magic)
+	e.uvarint(Version)
+	e.uvarint(uint64(len(headerJSON)))
+	e.w.Write(headerJSON)
+
+	e.node(result.Root, "")
+	if err := e.w.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+type encoder struct {
+	w       *bufio.Writer
+	buf     [binary.MaxVarintLen64]byte
+	strings map[string]uint64
+}
+
+func (e *encoder) uvarint(v uint64) {
+	n := binary.PutUvarint(e.buf[:], v)
+	e.w.Write(e.buf[:n])
+}
+
+func (e *encoder) varint(v int64) {
+	n := binary.PutVarint(e.buf[:], v)
+	e.w.Write(e.buf[:n])
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.w.WriteString(s)
+}
+
+// interned writes index+1 for a string already in the table, or 0 followed
+// by the string, which then joins the table.
+func (e *encoder) interned(s string) {
+	if i, ok := e.strings[s]; ok {
+		e.uvarint(i + 1)
+		return
+	}
+	e.strings[s] = uint64(len(e.strings))
+	e.uvarint(0)
+	e.string(s)
+}
+
+// node writes n and its subtree. bufio.Writer keeps the first write error,
+// which Flush reports, so individual writes are not checked.
+func (e *encoder) node(n *models.FileNode, parentPath string) {
+	var flags uint64
+	if n.Type == scanner.FileTypeDirectory {
+		flags |= flagDirectory
+	}
+	if n.IsHidden {
+		flags |= flagHidden
+	}
+	if n.IsVirtual {
+		flags |= flagVirtual
+	}
+	if n.IsBrokenLink {
+		flags |= flagBrokenLink
+	}
+	if parentPath == "" || n.Path != filepath.Join(parentPath, n.Name) {
+		flags |= flagPath
+	}
+	if n.ID != scanner.GenerateID(n.Path) {
+		flags |= flagID
+	}
+	if !n.LastModified.IsZero() {
+		flags |= flagModified
+	}
+	if !n.LastAccessed.IsZero() {
+		flags |= flagAccessed
+	}
+	if n.AllocatedSize != 0 {
+		flags |= flagAllocated
+	}
+	if n.Type != scanner.FileTypeDirectory && len(n.Children) > 0 {
+		flags |= flagChildren
+	}
+
+	e.uvarint(flags)
+	e.string(n.Name)
+	if flags&flagPath != 0 {
+		e.string(n.Path)
+	}
+	if flags&flagID != 0 {
+		e.string(n.ID)
+	}
+	e.varint(n.Size)
+	if n.IsVirtual {
+		e.varint(n.CompressedSize)
+	}
+	if flags&flagModified != 0 {
+		e.varint(n.LastModified.UnixNano())
+	}
+	if flags&flagAccessed != 0 {
+		e.varint(n.LastAccessed.UnixNano())
+	}
+	if flags&flagAllocated != 0 {
+		e.varint(n.AllocatedSize)
+	}
+	e.interned(n.Permissions)
+	e.interned(n.Owner)
+	e.interned(n.Group)
+	if n.IsBrokenLink {
+		e.string(n.LinkTarget)
+	}
+
+	if flags&(flagDirectory|flagChildren) != 0 {
+		e.uvarint(uint64(len(n.Children)))
+		for _, child := range n.Children {
+			e.node(child, n.Path)
+		}
+	}
+}
+
+// Read decodes a whole snapshot.
+func Read(r io.Reader) (*Snapshot, error) {
+	d, h, err := open(r)
+	if err != nil {
+		return nil, err
+	}
+	defer d.zr.Close()
+
+	root, err := d.node("", 0)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt snapshot: %w", err)
+	}
+	return &Snapshot{
+		Info: h.info(),
+		Result: &models.ScanResult{
+			Root:             root,
+			TotalSize:        h.TotalSize,
+			TotalFiles:       h.TotalFiles,
+			TotalDirectories: h.TotalDirectories,
+			ScanTime:         h.ScanTime,
+			ScanDurationMs:   h.ScanDurationMs,
+			FileTypes:        h.FileTypes,
+		},
+	}, nil
+}
+
+// ReadInfo decodes only the header, which is cheap enough for listings.
+func ReadInfo(r io.Reader) (*Info, error) {
+	d, h, err := open(r)
+	if err != nil {
+		return nil, err
+	}
+	d.zr.Close()
+	return h.info(), nil
+}
+
+func (h *header) info() *Info {
+	return &Info{
+		Label:            h.Label,
+		Path:             h.Path,
+		CreatedAt:        h.CreatedAt,
+		ScanTime:         h.ScanTime,
+		TotalSize:        h.TotalSize,
+		TotalFiles:       h.TotalFiles,
+		TotalDirectories: h.TotalDirectories,
+		Options:          h.Options,
+	}
+}
+
+type decoder struct {
+	zr      *gzip.Reader
+	r       *bufio.Reader
+	strings []string
+}
+
+func open(r io.Reader) (*decoder, *header, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a snapshot: %w", err)
+	}
+	d := &decoder{zr: zr, r: bufio.NewReader(zr), strings: []string{""}}
+
+	prefix := make([]byte, len(magic))
+	if _, err := io.ReadFull(d.r, prefix); err != nil || string(prefix) != magic {
+		zr.Close()
+		return nil, nil, fmt.Errorf("not a snapshot")
+	}
+	version, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		zr.Close()
+		return nil, nil, fmt.Errorf("corrupt snapshot: %w", err)
+	}
+	if version > Version {
+		zr.Close()
+		return nil, nil, fmt.Errorf("snapshot format version %d is newer than supported version %d", version, Version)
+	}
+
+	size, err := binary.ReadUvarint(d.r)
+	if err == nil && size > maxHeaderLen {
+		err = errors.New("header too large")
+	}
+	var h header
+	if err == nil {
+		headerJSON := make([]byte, size)
+		if _, err = io.ReadFull(d.r, headerJSON); err == nil {
+			err = json.Unmarshal(headerJSON, &h)
+		}
+	}
+	if err != nil {
+		zr.Close()
+		return nil, nil, fmt.Errorf("corrupt snapshot header: %w", err)
+	}
+	return d, &h, nil
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return "", err
+	}
+	if n > maxStringLen {
+		return "", errors.New("string too long")
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (d *decoder) interned() (string, error) {
+	i, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return "", err
+	}
+	if i == 0 {
+		s, err := d.string()
+		if err != nil {
+			return "", err
+		}
+		d.strings = append(d.strings, s)
+		return s, nil
+	}
+	if i > uint64(len(d.strings)) {
+		return "", fmt.Errorf("string reference %d out of range", i)
+	}
+	return d.strings[i-1], nil
+}
+
+func (d *decoder) node(parentPath string, depth int) (*models.FileNode, error) {
+	if depth > maxTreeDepth {
+		return nil, errors.New("tree too deep")
+	}
+	flags, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &models.FileNode{
+		Type:         scanner.FileTypeFile,
+		IsHidden:     flags&flagHidden != 0,
+		IsVirtual:    flags&flagVirtual != 0,
+		IsBrokenLink: flags&flagBrokenLink != 0,
+	}
+	if flags&flagDirectory != 0 {
+		n.Type = scanner.FileTypeDirectory
+	}
+	if n.Name, err = d.string(); err != nil {
+		return nil, err
+	}
+	n.Path = filepath.Join(parentPath, n.Name)
+	if flags&flagPath != 0 {
+		if n.Path, err = d.string(); err != nil {
+			return nil, err
+		}
+	}
+	n.ID = scanner.GenerateID(n.Path)
+	if flags&flagID != 0 {
+		if n.ID, err = d.string(); err != nil {
+			return nil, err
+		}
+	}
+	if n.Size, err = binary.ReadVarint(d.r); err != nil {
+		return nil, err
+	}
+	if n.IsVirtual {
+		if n.CompressedSize, err = binary.ReadVarint(d.r); err != nil {
+			return nil, err
+		}
+	}
+	if flags&flagModified != 0 {
+		if n.LastModified, err = d.time(); err != nil {
+			return nil, err
+		}
+	}
+	if flags&flagAccessed != 0 {
+		if n.LastAccessed, err = d.time(); err != nil {
+			return nil, err
+		}
+	}
+	if flags&flagAllocated != 0 {
+		if n.AllocatedSize, err = binary.ReadVarint(d.r); err != nil {
+			return nil, err
+		}
+	}
+	if n.Permissions, err = d.interned(); err != nil {
+		return nil, err
+	}
+	if n.Owner, err = d.interned(); err != nil {
+		return nil, err
+	}
+	if n.Group, err = d.interned(); err != nil {
+		return nil, err
+	}
+	if n.IsBrokenLink {
+		if n.LinkTarget, err = d.string(); err != nil {
+			return nil, err
+		}
+	}
+
+	if flags&(flagDirectory|flagChildren) != 0 {
+		count, err := binary.ReadUvarint(d.r)
+		if err != nil {
+			return nil, err
+		}
+		n.Children = make([]*models.FileNode, 0, min(count, maxPrealloc))
+		for range count {
+			child, err := d.node(n.Path, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			n.Children = append(n.Children, child)
+		}
+	}
+	return n, nil
+}
+
+func (d *decoder) time() (time.Time, error) {
+	nanos, err := binary.ReadVarint(d.r)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, nanos), nil
+}
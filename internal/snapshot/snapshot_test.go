@@ -0,0 +1,226 @@
+package snapshot
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+func scanTestTree(t *testing.T) *models.ScanResult {
+	t.Helper()
+	m := vfs.NewMemFS()
+	m.AddUser(1000, "alice")
+	m.AddGroup(1000, "staff")
+	_ = m.WriteFile("/data/src/main.go", make([]byte, 120))
+	_ = m.WriteFile("/data/src/.env", make([]byte, 8))
+	_ = m.WriteFile("/data/photos/a.jpg", make([]byte, 4000))
+	_ = m.MkdirAll("/data/empty")
+	_ = m.Symlink("/data/gone", "/data/link")
+	_ = m.Chown("/data/photos/a.jpg", 1000, 1000)
+	_ = m.SetAllocated("/data/photos/a.jpg", 4096)
+	_ = m.Chtimes("/data/photos/a.jpg", time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local))
+
+	options := scanner.DefaultScanOptions()
+	options.ShowHiddenFiles = true
+	result, err := scanner.NewScannerWithFS(m, options).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	result.FileTypes = &models.TypeBreakdown{Extensions: []*models.TypeStat{{Key: ".jpg", Size: 4000, Count: 1}}}
+
+	// A virtual entry whose ID and path do not follow the scanner's scheme
+	var photos *models.FileNode
+	for _, child := range result.Root.Children {
+		if child.Name == "photos" {
+			photos = child
+		}
+	}
+	photos.Children = append(photos.Children, &models.FileNode{
+		ID:             "custom",
+		Name:           "inner.txt",
+		Path:           "/data/photos/a.zip/inner.txt",
+		Size:           10,
+		Type:           scanner.FileTypeFile,
+		IsVirtual:      true,
+		CompressedSize: 4,
+	})
+	return result
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRoundTrip(t *testing.T) {
+	result := scanTestTree(t)
+	options := scanner.DefaultScanOptions()
+
+	var buf bytes.Buffer
+	if err := Write(&buf, result, options, "before cleanup"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	snap, err := Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if got, want := mustJSON(t, snap.Result), mustJSON(t, result); got != want {
+		t.Errorf("round trip changed the result\n got: %s\nwant: %s", got, want)
+	}
+	if snap.Info.Label != "before cleanup" || snap.Info.Path != "/data" || snap.Info.TotalSize != result.TotalSize {
+		t.Errorf("Info = %+v", snap.Info)
+	}
+	if snap.Info.Options == nil || !snap.Info.Options.RespectGitignore || len(snap.Info.Options.ExcludePatterns) != 3 {
+		t.Errorf("Options = %+v, want the defaults", snap.Info.Options)
+	}
+
+	info, err := ReadInfo(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadInfo() error = %v", err)
+	}
+	if info.TotalFiles != result.TotalFiles {
+		t.Errorf("ReadInfo().TotalFiles = %d, want %d", info.TotalFiles, result.TotalFiles)
+	}
+}
+
+func TestRoundTrip_ExpandedArchive(t *testing.T) {
+	var zipData bytes.Buffer
+	zw := zip.NewWriter(&zipData)
+	for _, name := range []string{"README.md", "src/main.go"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte("content of " + name))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/bundle.zip", zipData.Bytes())
+
+	options := scanner.DefaultScanOptions()
+	options.ExpandArchives = true
+	result, err := scanner.NewScannerWithFS(m, options).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+	if bundle := result.Root.Children[0]; bundle.Type != scanner.FileTypeFile || len(bundle.Children) != 2 {
+		t.Fatalf("bundle.zip = %+v, want a file with the archive entries", bundle)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, result, options, ""); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	snap, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got, want := mustJSON(t, snap.Result), mustJSON(t, result); got != want {
+		t.Errorf("round trip changed the result\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestReadRejectsBadInput(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, scanTestTree(t), nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not gzip", []byte("hello"), "not a snapshot"},
+		{"truncated", data[:len(data)/2], "corrupt snapshot"},
+		{"newer version", encodeRaw(t, magic+"\x07"), "newer than supported"},
+		{"wrong magic", encodeRaw(t, "NOTSNAP"), "not a snapshot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Read() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func encodeRaw(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(s))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir() + "/snapshots")
+
+	infos, err := store.List()
+	if err != nil || len(infos) != 0 {
+		t.Fatalf("List() on a missing directory = %v, %v", infos, err)
+	}
+
+	result := scanTestTree(t)
+	first, err := store.Save(result, nil, "first")
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	second, err := store.Save(result, nil, "second")
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if first.FileSize <= 0 || first.ID == second.ID {
+		t.Errorf("Save() infos = %+v, %+v", first, second)
+	}
+
+	infos, err = store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(infos) != 2 || infos[0].Label != "second" || infos[1].Label != "first" {
+		t.Fatalf("List() = %+v, want second then first", infos)
+	}
+
+	snap, err := store.Load(first.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if snap.Result.TotalSize != result.TotalSize || snap.Info.ID != first.ID {
+		t.Errorf("Load() = %+v", snap.Info)
+	}
+
+	if err := store.Delete(first.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if infos, _ := store.List(); len(infos) != 1 {
+		t.Errorf("List() after Delete = %d snapshots, want 1", len(infos))
+	}
+
+	for _, id := range []string{"", "../secret", "a/b", ".."} {
+		if _, err := store.Load(id); err == nil || !strings.Contains(err.Error(), "invalid snapshot id") {
+			t.Errorf("Load(%q) error = %v, want invalid id", id, err)
+		}
+	}
+}
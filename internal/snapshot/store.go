@@ -0,0 +1,172 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+// Extension is the file extension of snapshots in a Store.
+const Extension = ".vzsnap"
+
+// Store keeps snapshots as individual files in one directory, which is
+// created on the first save.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Save writes result to a new snapshot and returns its description. The file
+// only appears under its final name once it is complete.
+func (s *Store) Save(result *models.ScanResult, options *scanner.ScanOptions, label string) (*Info, error) {
+	if result == nil || result.Root == nil {
+		return nil, fmt.Errorf("no scan result to save")
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Write(tmp, result, options, label); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	id := newID(result.Root.Path, time.Now())
+	path := filepath.Join(s.dir, id+Extension)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	return s.info(id)
+}
+
+// newID names a snapshot after its creation time and scanned path, which
+// sorts by time and never needs escaping.
+func newID(rootPath string, now time.Time) string {
+	return now.UTC().Format("20060102-150405.000") + "-" + scanner.GenerateID(rootPath)[:8]
+}
+
+// List describes every snapshot in the store, newest first. Unreadable
+// files are skipped.
+func (s *Store) List() ([]*Info, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []*Info{}, nil
+		}
+		return nil, err
+	}
+
+	infos := []*Info{}
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), Extension)
+		if !ok || entry.IsDir() || !validID(id) {
+			continue
+		}
+		if info, err := s.info(id); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.After(infos[j].CreatedAt)
+	})
+	return infos, nil
+}
+
+func (s *Store) info(id string) (*Info, error) {
+	f, err := os.Open(filepath.Join(s.dir, id+Extension))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := ReadInfo(f)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	info.ID = id
+	info.FileSize = stat.Size()
+	return info, nil
+}
+
+// Load reads the snapshot with the given ID.
+func (s *Store) Load(id string) (*Snapshot, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snap, err := Read(f)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	snap.Info.ID = id
+	snap.Info.FileSize = stat.Size()
+	return snap, nil
+}
+
+// Delete removes the snapshot with the given ID.
+func (s *Store) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (s *Store) path(id string) (string, error) {
+	if !validID(id) {
+		return "", fmt.Errorf("invalid snapshot id: %q", id)
+	}
+	return filepath.Join(s.dir, id+Extension), nil
+}
+
+// validID accepts the characters newID produces, which keeps IDs from the
+// frontend from escaping the store directory.
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return !strings.Contains(id, "..")
+}
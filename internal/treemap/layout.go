@@ -0,0 +1,220 @@
+// Package treemap lays out a scanned tree as nested squarified rectangles,
+// following Bruls, Huizing and van Wijk, "Squarified Treemaps" (2000).
+package treemap
+
+import (
+	"math"
+	"sort"
+
+	"vizdisk/internal/models"
+	"vizdisk/internal/scanner"
+)
+
+type Rect struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+func (r Rect) Area() float64 {
+	return r.W * r.H
+}
+
+// Tile is one laid out node. Depth is relative to the layout root, which is
+// depth 0. Truncated is set on directories whose children were not laid out
+// because of MaxDepth or MinArea.
+type Tile struct {
+	Rect
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Type      string `json:"type"`
+	Depth     int    `json:"depth"`
+	IsVirtual bool   `json:"isVirtual,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+type Options struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	// MaxDepth limits how many levels below the root are laid out; zero
+	// means no limit
+	MaxDepth int `json:"maxDepth"`
+	// MinArea drops tiles smaller than this many square pixels
+	MinArea float64 `json:"minArea"`
+	// Padding insets children from their parent's edges and Header
+	// reserves space above them for the parent's label
+	Padding float64 `json:"padding"`
+	Header  float64 `json:"header"`
+}
+
+func DefaultOptions() *Options {
+	return &Options{
+		Width:    1200,
+		Height:   800,
+		MaxDepth: 4,
+		MinArea:  16,
+		Padding:  1,
+		Header:   14,
+	}
+}
+
+// Layout returns the tiles of root and its descendants in drawing order,
+// parents before children. The root fills the whole viewport.
+func Layout(root *models.FileNode, options *Options) []*Tile {
+	if options == nil {
+		options = DefaultOptions()
+	}
+	if root == nil || options.Width <= 0 || options.Height <= 0 {
+		return []*Tile{}
+	}
+
+	l := &layout{options: options}
+	l.place(root, Rect{W: options.Width, H: options.Height}, 0)
+	return l.tiles
+}
+
+type layout struct {
+	options *Options
+	tiles   []*Tile
+}
+
+func (l *layout) place(node *models.FileNode, rect Rect, depth int) {
+	tile := &Tile{
+		Rect:      rect,
+		ID:        node.ID,
+		Name:      node.Name,
+		Path:      node.Path,
+		Size:      node.Size,
+		Type:      node.Type,
+		Depth:     depth,
+		IsVirtual: node.IsVirtual,
+	}
+	l.tiles = append(l.tiles, tile)
+
+	if len(node.Children) == 0 || node.Size <= 0 {
+		return
+	}
+	// Archives are files with virtual children and are laid out like
+	// directories
+	if node.Type == scanner.FileTypeFile && !hasVirtualChildren(node) {
+		return
+	}
+
+	inner := Rect{
+		X: rect.X + l.options.Padding,
+		Y: rect.Y + l.options.Padding + l.options.Header,
+		W: rect.W - 2*l.options.Padding,
+		H: rect.H - 2*l.options.Padding - l.options.Header,
+	}
+	if (l.options.MaxDepth > 0 && depth >= l.options.MaxDepth) || inner.W <= 0 || inner.H <= 0 || inner.Area() < l.options.MinArea {
+		tile.Truncated = true
+		return
+	}
+
+	children := make([]*models.FileNode, 0, len(node.Children))
+	for _, child := range node.Children {
+		if child.Size > 0 {
+			children = append(children, child)
+		}
+	}
+	sort.SliceStable(children, func(i, j int) bool { return children[i].Size > children[j].Size })
+
+	sizes := make([]float64, len(children))
+	for i, child := range children {
+		sizes[i] = float64(child.Size)
+	}
+	for i, childRect := range Squarify(sizes, inner) {
+		if childRect.Area() < l.options.MinArea {
+			// Children are sorted, so the rest are at most as large
+			tile.Truncated = true
+			break
+		}
+		l.place(children[i], childRect, depth+1)
+	}
+}
+
+func hasVirtualChildren(node *models.FileNode) bool {
+	return len(node.Children) > 0 && node.Children[0].IsVirtual
+}
+
+// Squarify divides rect into rectangles with areas proportional to sizes,
+// keeping aspect ratios close to one. Sizes should be sorted in decreasing
+// order; the returned rectangles are in the same order.
+func Squarify(sizes []float64, rect Rect) []Rect {
+	rects := make([]Rect, len(sizes))
+	var total float64
+	for _, s := range sizes {
+		total += s
+	}
+	if total <= 0 || rect.Area() <= 0 {
+		return rects
+	}
+
+	scale := rect.Area() / total
+	areas := make([]float64, len(sizes))
+	for i, s := range sizes {
+		areas[i] = s * scale
+	}
+
+	start := 0
+	for start < len(areas) {
+		side := math.Min(rect.W, rect.H)
+		end := start + 1
+		rowSum := areas[start]
+		for end < len(areas) {
+			next := rowSum + areas[end]
+			if worst(areas[start:end+1], next, side) > worst(areas[start:end], rowSum, side) {
+				break
+			}
+			rowSum = next
+			end++
+		}
+		rect = layoutRow(areas[start:end], rowSum, rect, rects[start:end])
+		start = end
+	}
+	return rects
+}
+
+// worst returns the largest aspect ratio in a row of areas laid along a side
+// of the given length.
+func worst(row []float64, sum, side float64) float64 {
+	if sum <= 0 {
+		return math.Inf(1)
+	}
+	rmax, rmin := row[0], row[0]
+	for _, a := range row {
+		rmax = math.Max(rmax, a)
+		rmin = math.Min(rmin, a)
+	}
+	s2, w2 := sum*sum, side*side
+	return math.Max(w2*rmax/s2, s2/(w2*rmin))
+}
+
+// layoutRow places a row along the shorter side of rect, fills out, and
+// returns the remaining space.
+func layoutRow(row []float64, sum float64, rect Rect, out []Rect) Rect {
+	if rect.W >= rect.H {
+		// Column on the left
+		width := sum / rect.H
+		y := rect.Y
+		for i, a := range row {
+			h := a / width
+			out[i] = Rect{X: rect.X, Y: y, W: width, H: h}
+			y += h
+		}
+		return Rect{X: rect.X + width, Y: rect.Y, W: rect.W - width, H: rect.H}
+	}
+
+	// Row across the top
+	height := sum / rect.W
+	x := rect.X
+	for i, a := range row {
+		w := a / height
+		out[i] = Rect{X: x, Y: rect.Y, W: w, H: height}
+		x += w
+	}
+	return Rect{X: rect.X, Y: rect.Y + height, W: rect.W, H: rect.H - height}
+}
@@ -0,0 +1,84 @@
+package treemap
+
+import (
+	"math"
+	"testing"
+
+	"vizdisk/internal/scanner"
+	"vizdisk/internal/vfs"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestSquarify(t *testing.T) {
+	// The example from the squarified treemap paper
+	sizes := []float64{6, 6, 4, 3, 2, 2, 1}
+	rect := Rect{W: 6, H: 4}
+	rects := Squarify(sizes, rect)
+
+	if r := rects[0]; !approx(r.W, 3) || !approx(r.H, 2) || r.X != 0 || r.Y != 0 {
+		t.Errorf("rects[0] = %+v, want 3x2 at the origin", r)
+	}
+	if r := rects[1]; !approx(r.Y, 2) || !approx(r.W, 3) {
+		t.Errorf("rects[1] = %+v, want below rects[0]", r)
+	}
+
+	var total float64
+	for i, r := range rects {
+		if !approx(r.Area(), sizes[i]) {
+			t.Errorf("rects[%d] area = %v, want %v", i, r.Area(), sizes[i])
+		}
+		if r.X < -1e-9 || r.Y < -1e-9 || r.X+r.W > rect.W+1e-9 || r.Y+r.H > rect.H+1e-9 {
+			t.Errorf("rects[%d] = %+v lies outside the viewport", i, r)
+		}
+		if ratio := math.Max(r.W/r.H, r.H/r.W); ratio > 3 {
+			t.Errorf("rects[%d] aspect ratio = %v", i, ratio)
+		}
+		total += r.Area()
+	}
+	if !approx(total, rect.Area()) {
+		t.Errorf("total area = %v, want %v", total, rect.Area())
+	}
+}
+
+func TestLayout(t *testing.T) {
+	m := vfs.NewMemFS()
+	_ = m.WriteFile("/data/big/a", make([]byte, 6000))
+	_ = m.WriteFile("/data/big/deep/b", make([]byte, 3000))
+	_ = m.WriteFile("/data/small/c", make([]byte, 1000))
+	_ = m.WriteFile("/data/tiny", make([]byte, 1))
+	_ = m.WriteFile("/data/empty", nil)
+	result, err := scanner.NewScannerWithFS(m, scanner.DefaultScanOptions()).ScanPath("/data", nil)
+	if err != nil {
+		t.Fatalf("ScanPath() error = %v", err)
+	}
+
+	tiles := Layout(result.Root, &Options{Width: 100, Height: 100, MaxDepth: 2, MinArea: 4})
+	byPath := make(map[string]*Tile)
+	for _, tile := range tiles {
+		byPath[tile.Path] = tile
+	}
+
+	if root := tiles[0]; root.Path != "/data" || root.W != 100 || root.H != 100 {
+		t.Errorf("tiles[0] = %+v, want the root filling the viewport", root)
+	}
+	if byPath["/data/tiny"] != nil || byPath["/data/empty"] != nil {
+		t.Error("tiles below MinArea or of empty files should be dropped")
+	}
+	if !byPath["/data"].Truncated {
+		t.Error("root should be marked truncated when children are dropped")
+	}
+	deep := byPath["/data/big/deep"]
+	if deep == nil || deep.Depth != 2 || !deep.Truncated || byPath["/data/big/deep/b"] != nil {
+		t.Errorf("deep = %+v, want truncated tile at MaxDepth", deep)
+	}
+	big, small := byPath["/data/big"], byPath["/data/small"]
+	if big == nil || small == nil || big.Area() < 8*small.Area() {
+		t.Errorf("big = %+v, small = %+v, want areas proportional to size", big, small)
+	}
+	if a := byPath["/data/big/a"]; a == nil || a.X < big.X || a.Y < big.Y || a.X+a.W > big.X+big.W+1e-9 {
+		t.Errorf("a = %+v, want inside big %+v", a, big)
+	}
+}
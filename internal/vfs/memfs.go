@@ -0,0 +1,416 @@
+package vfs
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const maxSymlinkHops = 40
+
+// MemFS is an in-memory FS for building deterministic trees in tests. Besides
+// plain files, directories and symlinks it can inject errors for individual
+// paths to simulate permission problems or entries vanishing mid-scan.
+type MemFS struct {
+	mu     sync.RWMutex
+	root   *memNode
+	errs   map[string]error
+	users  map[uint32]string
+	groups map[uint32]string
+	inodes uint64
+}
+
+type memNode struct {
+	name     string
+	mode     fs.FileMode
+	modTime  time.Time
+	data     []byte
+	target   string
+	children map[string]*memNode
+	meta     Metadata
+	ino      uint64
+}
+
+func NewMemFS() *MemFS {
+	return &MemFS{
+		root:   &memNode{name: "/", mode: fs.ModeDir | 0o755, children: map[string]*memNode{}, ino: 1},
+		errs:   make(map[string]error),
+		users:  make(map[uint32]string),
+		groups: make(map[uint32]string),
+		inodes: 1,
+	}
+}
+
+// MkdirAll creates a directory and any missing parents.
+func (m *MemFS) MkdirAll(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.mkdirAll(splitPath(name))
+	return err
+}
+
+// WriteFile creates or replaces a regular file, creating parents as needed.
+func (m *MemFS) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.create(name, &memNode{mode: 0o644, data: data})
+}
+
+// Symlink creates name as a symbolic link pointing at target.
+func (m *MemFS) Symlink(target, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.create(name, &memNode{mode: fs.ModeSymlink | 0o777, target: target})
+}
+
+// Link creates newname as a hard link to the file oldname, so both report
+// the same inode.
+func (m *MemFS) Link(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, err := m.lookup(splitPath(oldname), false, 0)
+	if err != nil {
+		return &fs.PathError{Op: "link", Path: oldname, Err: err}
+	}
+	if node.mode.IsDir() {
+		return &fs.PathError{Op: "link", Path: oldname, Err: syscall.EPERM}
+	}
+	return m.create(newname, node)
+}
+
+// Remove deletes a path and everything below it.
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	parts := splitPath(name)
+	if len(parts) == 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+	parent, err := m.lookup(parts[:len(parts)-1], true, 0)
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: err}
+	}
+	if _, ok := parent.children[parts[len(parts)-1]]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(parent.children, parts[len(parts)-1])
+	return nil
+}
+
+// Chtimes sets the modification time of a path without following symlinks.
+func (m *MemFS) Chtimes(name string, modTime time.Time) error {
+	return m.update("chtimes", name, func(n *memNode) { n.modTime = modTime })
+}
+
+// SetAccessTime sets the access time reported through MetadataOf.
+func (m *MemFS) SetAccessTime(name string, accessTime time.Time) error {
+	return m.update("chtimes", name, func(n *memNode) { n.meta.AccessTime = accessTime })
+}
+
+// SetAllocated sets the allocated size reported through MetadataOf.
+func (m *MemFS) SetAllocated(name string, allocated int64) error {
+	return m.update("setallocated", name, func(n *memNode) { n.meta.Allocated = allocated })
+}
+
+// Chown sets the owning user and group IDs of a path without following
+// symlinks.
+func (m *MemFS) Chown(name string, uid, gid uint32) error {
+	return m.update("chown", name, func(n *memNode) {
+		n.meta.HasOwner = true
+		n.meta.UID = uid
+		n.meta.GID = gid
+	})
+}
+
+// AddUser registers the name reported for a user ID.
+func (m *MemFS) AddUser(uid uint32, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.users[uid] = name
+}
+
+// AddGroup registers the name reported for a group ID.
+func (m *MemFS) AddGroup(gid uint32, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.groups[gid] = name
+}
+
+// Chmod replaces the permission bits of a path without following symlinks.
+func (m *MemFS) Chmod(name string, perm fs.FileMode) error {
+	return m.update("chmod", name, func(n *memNode) { n.mode = n.mode.Type() | perm.Perm() })
+}
+
+// FailStat makes Stat and Lstat of name return err while the entry keeps
+// showing up in its parent's listing, like a file deleted during a scan.
+func (m *MemFS) FailStat(name string, err error) {
+	m.setErr("stat", name, err)
+}
+
+// FailReadDir makes ReadDir of name return err, like an unreadable directory.
+func (m *MemFS) FailReadDir(name string, err error) {
+	m.setErr("readdir", name, err)
+}
+
+// FailOpen makes Open of name return err.
+func (m *MemFS) FailOpen(name string, err error) {
+	m.setErr("open", name, err)
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	return m.stat("stat", name, true)
+}
+
+func (m *MemFS) Lstat(name string) (fs.FileInfo, error) {
+	return m.stat("lstat", name, false)
+}
+
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if err := m.injected("readdir", name); err != nil {
+		return nil, err
+	}
+	node, err := m.lookup(splitPath(name), true, 0)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	if !node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: syscall.ENOTDIR}
+	}
+
+	names := make([]string, 0, len(node.children))
+	for childName := range node.children {
+		names = append(names, childName)
+	}
+	sort.Strings(names)
+
+	entries := make([]fs.DirEntry, 0, len(names))
+	for _, childName := range names {
+		entries = append(entries, fs.FileInfoToDirEntry(m.info(childName, node.children[childName])))
+	}
+	return entries, nil
+}
+
+func (m *MemFS) Readlink(name string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, err := m.lookup(splitPath(name), false, 0)
+	if err != nil {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: err}
+	}
+	if node.mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return node.target, nil
+}
+
+func (m *MemFS) Open(name string) (File, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if err := m.injected("open", name); err != nil {
+		return nil, err
+	}
+	node, err := m.lookup(splitPath(name), true, 0)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &memFile{
+		Reader: bytes.NewReader(node.data),
+		info:   m.info(path.Base(filepath.ToSlash(name)), node),
+	}, nil
+}
+
+func (m *MemFS) SameFile(fi1, fi2 fs.FileInfo) bool {
+	a, ok1 := fi1.(*memInfo)
+	b, ok2 := fi2.(*memInfo)
+	return ok1 && ok2 && a.node == b.node
+}
+
+func (m *MemFS) stat(op, name string, follow bool) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if err := m.injected("stat", name); err != nil {
+		return nil, err
+	}
+	node, err := m.lookup(splitPath(name), follow, 0)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return m.info(path.Base(filepath.ToSlash(filepath.Clean(name))), node), nil
+}
+
+// info snapshots node, resolving owner names while the lock is held.
+func (m *MemFS) info(name string, node *memNode) *memInfo {
+	meta := node.meta
+	meta.Device, meta.Inode = 1, node.ino
+	if meta.HasOwner {
+		meta.Owner = m.users[meta.UID]
+		if meta.Owner == "" {
+			meta.Owner = strconv.FormatUint(uint64(meta.UID), 10)
+		}
+		meta.Group = m.groups[meta.GID]
+		if meta.Group == "" {
+			meta.Group = strconv.FormatUint(uint64(meta.GID), 10)
+		}
+	}
+	return &memInfo{name: name, node: node, meta: meta}
+}
+
+func (m *MemFS) update(op, name string, fn func(*memNode)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, err := m.lookup(splitPath(name), false, 0)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	fn(node)
+	return nil
+}
+
+func (m *MemFS) create(name string, node *memNode) error {
+	parts := splitPath(name)
+	if len(parts) == 0 {
+		return &fs.PathError{Op: "create", Path: name, Err: fs.ErrExist}
+	}
+	parent, err := m.mkdirAll(parts[:len(parts)-1])
+	if err != nil {
+		return &fs.PathError{Op: "create", Path: name, Err: err}
+	}
+	if existing, ok := parent.children[parts[len(parts)-1]]; ok && existing.mode.IsDir() {
+		return &fs.PathError{Op: "create", Path: name, Err: syscall.EISDIR}
+	}
+	if node.ino == 0 {
+		node.ino = m.newInode()
+	}
+	node.name = parts[len(parts)-1]
+	parent.children[node.name] = node
+	return nil
+}
+
+func (m *MemFS) mkdirAll(parts []string) (*memNode, error) {
+	node := m.root
+	for _, part := range parts {
+		child, ok := node.children[part]
+		if !ok {
+			child = &memNode{name: part, mode: fs.ModeDir | 0o755, children: map[string]*memNode{}, ino: m.newInode()}
+			node.children[part] = child
+		}
+		if !child.mode.IsDir() {
+			return nil, syscall.ENOTDIR
+		}
+		node = child
+	}
+	return node, nil
+}
+
+func (m *MemFS) newInode() uint64 {
+	m.inodes++
+	return m.inodes
+}
+
+// lookup resolves path components from the root, following symlinks in
+// intermediate components and, when follow is set, in the final one.
+func (m *MemFS) lookup(parts []string, follow bool, hops int) (*memNode, error) {
+	node := m.root
+	for i, part := range parts {
+		if !node.mode.IsDir() {
+			return nil, syscall.ENOTDIR
+		}
+		child, ok := node.children[part]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		last := i == len(parts)-1
+		if child.mode&fs.ModeSymlink != 0 && (!last || follow) {
+			if hops >= maxSymlinkHops {
+				return nil, syscall.ELOOP
+			}
+			target := filepath.ToSlash(child.target)
+			if !path.IsAbs(target) {
+				target = path.Join("/"+strings.Join(parts[:i], "/"), target)
+			}
+			resolved, err := m.lookup(splitPath(target), true, hops+1)
+			if err != nil {
+				return nil, err
+			}
+			child = resolved
+		}
+		node = child
+	}
+	return node, nil
+}
+
+func (m *MemFS) setErr(op, name string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.errs[errKey(op, name)] = err
+}
+
+func (m *MemFS) injected(op, name string) error {
+	if err, ok := m.errs[errKey(op, name)]; ok {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return nil
+}
+
+func errKey(op, name string) string {
+	return fmt.Sprintf("%s:/%s", op, strings.Join(splitPath(name), "/"))
+}
+
+func splitPath(name string) []string {
+	name = filepath.ToSlash(filepath.Clean(name))
+	name = strings.TrimPrefix(name, filepath.ToSlash(filepath.VolumeName(name)))
+	var parts []string
+	for _, part := range strings.Split(name, "/") {
+		if part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+type memInfo struct {
+	name string
+	node *memNode
+	meta Metadata
+}
+
+func (i *memInfo) Name() string       { return i.name }
+func (i *memInfo) Size() int64        { return int64(len(i.node.data)) }
+func (i *memInfo) Mode() fs.FileMode  { return i.node.mode }
+func (i *memInfo) ModTime() time.Time { return i.node.modTime }
+func (i *memInfo) IsDir() bool        { return i.node.mode.IsDir() }
+func (i *memInfo) Sys() any {
+	meta := i.meta
+	return &meta
+}
+
+type memFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
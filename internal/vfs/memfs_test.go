@@ -0,0 +1,131 @@
+package vfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+func TestMemFS_StatAndReadDir(t *testing.T) {
+	m := NewMemFS()
+	_ = m.WriteFile("/root/b.txt", []byte("hello"))
+	_ = m.WriteFile("/root/a/c.txt", []byte("x"))
+	_ = m.Symlink("a", "/root/link")
+
+	entries, err := m.ReadDir("/root")
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"a", "b.txt", "link"}; len(names) != len(want) || names[0] != want[0] || names[1] != want[1] || names[2] != want[2] {
+		t.Errorf("ReadDir() names = %v, want %v", names, want)
+	}
+	if entries[2].Type()&fs.ModeSymlink == 0 {
+		t.Errorf("ReadDir() link entry type = %v, want symlink", entries[2].Type())
+	}
+
+	info, err := m.Stat("/root/b.txt")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Size() != 5 || info.IsDir() {
+		t.Errorf("Stat() size = %d, isDir = %v", info.Size(), info.IsDir())
+	}
+
+	info, err = m.Stat("/root/link/c.txt")
+	if err != nil {
+		t.Fatalf("Stat() through symlink error = %v", err)
+	}
+	if info.Size() != 1 {
+		t.Errorf("Stat() through symlink size = %d, want 1", info.Size())
+	}
+
+	if _, err := m.Stat("/root/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat() missing error = %v, want ErrNotExist", err)
+	}
+}
+
+func TestMemFS_Lstat(t *testing.T) {
+	m := NewMemFS()
+	_ = m.MkdirAll("/d")
+	_ = m.Symlink("/d", "/link")
+
+	info, err := m.Lstat("/link")
+	if err != nil {
+		t.Fatalf("Lstat() error = %v", err)
+	}
+	if info.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("Lstat() mode = %v, want symlink", info.Mode())
+	}
+
+	target, err := m.Readlink("/link")
+	if err != nil || target != "/d" {
+		t.Errorf("Readlink() = %q, %v, want /d", target, err)
+	}
+
+	linked, _ := m.Stat("/link")
+	dir, _ := m.Stat("/d")
+	if !m.SameFile(linked, dir) {
+		t.Error("SameFile() = false for symlink and its target")
+	}
+}
+
+func TestMemFS_SymlinkLoop(t *testing.T) {
+	m := NewMemFS()
+	_ = m.Symlink("/b", "/a")
+	_ = m.Symlink("/a", "/b")
+
+	if _, err := m.Stat("/a"); err == nil {
+		t.Error("Stat() of symlink loop should fail")
+	}
+}
+
+func TestMemFS_InjectedErrors(t *testing.T) {
+	m := NewMemFS()
+	_ = m.WriteFile("/dir/gone.txt", []byte("x"))
+	m.FailStat("/dir/gone.txt", fs.ErrNotExist)
+	m.FailReadDir("/dir", fs.ErrPermission)
+
+	if _, err := m.Stat("/dir/gone.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat() error = %v, want ErrNotExist", err)
+	}
+	if _, err := m.ReadDir("/dir"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("ReadDir() error = %v, want ErrPermission", err)
+	}
+}
+
+func TestMemFS_OpenAndChtimes(t *testing.T) {
+	m := NewMemFS()
+	_ = m.WriteFile("/f", []byte("content"))
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	_ = m.Chtimes("/f", modTime)
+
+	f, err := m.Open("/f")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer f.Close()
+
+	data, _ := io.ReadAll(f)
+	if string(data) != "content" {
+		t.Errorf("ReadAll() = %q, want %q", data, "content")
+	}
+
+	info, _ := f.Stat()
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("ModTime() = %v, want %v", info.ModTime(), modTime)
+	}
+
+	if err := m.Remove("/f"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := m.Open("/f"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open() after Remove error = %v, want ErrNotExist", err)
+	}
+}
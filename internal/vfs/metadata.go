@@ -0,0 +1,75 @@
+package vfs
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Metadata holds file attributes that fs.FileInfo does not expose portably.
+// Zero values mean the attribute is unknown on this platform.
+type Metadata struct {
+	AccessTime time.Time
+	HasOwner   bool
+	UID        uint32
+	GID        uint32
+	Owner      string
+	Group      string
+	// Allocated is the space the file's blocks take on disk, which differs
+	// from its size for sparse and compressed files and small files rounded
+	// up to a block
+	Allocated int64
+	// Device and Inode identify the file itself rather than the path to it,
+	// so hard links and symlinks to one file can be told apart from copies.
+	// Inode is zero where the platform does not report it.
+	Device uint64
+	Inode  uint64
+}
+
+// MetadataOf extracts Metadata from info. MemFS provides it directly, while
+// for the OS filesystem it is read from the platform specific Sys() value and
+// owner IDs are resolved to names.
+func MetadataOf(info fs.FileInfo) Metadata {
+	if md, ok := info.Sys().(*Metadata); ok && md != nil {
+		return *md
+	}
+
+	md := sysMetadata(info.Sys())
+	if md.HasOwner {
+		md.Owner = lookupName(&userNames, md.UID, func(id string) (string, error) {
+			u, err := user.LookupId(id)
+			if err != nil {
+				return "", err
+			}
+			return u.Username, nil
+		})
+		md.Group = lookupName(&groupNames, md.GID, func(id string) (string, error) {
+			g, err := user.LookupGroupId(id)
+			if err != nil {
+				return "", err
+			}
+			return g.Name, nil
+		})
+	}
+	return md
+}
+
+var userNames, groupNames sync.Map
+
+// lookupName resolves an ID once and caches the answer, falling back to the
+// numeric ID for accounts that no longer exist.
+func lookupName(cache *sync.Map, id uint32, lookup func(string) (string, error)) string {
+	if name, ok := cache.Load(id); ok {
+		return name.(string)
+	}
+
+	key := strconv.FormatUint(uint64(id), 10)
+	name, err := lookup(key)
+	if err != nil || name == "" {
+		name = key
+	}
+	cache.Store(id, name)
+	return name
+}
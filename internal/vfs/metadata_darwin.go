@@ -0,0 +1,23 @@
+package vfs
+
+import (
+	"syscall"
+	"time"
+)
+
+func sysMetadata(sys any) Metadata {
+	st, ok := sys.(*syscall.Stat_t)
+	if !ok {
+		return Metadata{}
+	}
+	return Metadata{
+		HasOwner:   true,
+		UID:        st.Uid,
+		GID:        st.Gid,
+		AccessTime: time.Unix(st.Atimespec.Sec, st.Atimespec.Nsec),
+		// st_blocks is in 512-byte units regardless of the block size
+		Allocated: st.Blocks * 512,
+		Device:    uint64(st.Dev),
+		Inode:     uint64(st.Ino),
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !windows
+
+package vfs
+
+func sysMetadata(sys any) Metadata {
+	return Metadata{}
+}
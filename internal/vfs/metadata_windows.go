@@ -0,0 +1,16 @@
+package vfs
+
+import (
+	"syscall"
+	"time"
+)
+
+func sysMetadata(sys any) Metadata {
+	data, ok := sys.(*syscall.Win32FileAttributeData)
+	if !ok {
+		return Metadata{}
+	}
+	return Metadata{
+		AccessTime: time.Unix(0, data.LastAccessTime.Nanoseconds()),
+	}
+}
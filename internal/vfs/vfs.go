@@ -0,0 +1,62 @@
+package vfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// FS is the minimal set of filesystem operations the scanner and analyzers
+// need. Paths are always passed in the host's native form.
+type FS interface {
+	Stat(name string) (fs.FileInfo, error)
+	Lstat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Readlink(name string) (string, error)
+	Open(name string) (File, error)
+	SameFile(fi1, fi2 fs.FileInfo) bool
+}
+
+// File is an open file. *os.File satisfies it.
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+	Stat() (fs.FileInfo, error)
+}
+
+// OSFS reads the live operating system filesystem.
+type OSFS struct{}
+
+func NewOSFS() *OSFS {
+	return &OSFS{}
+}
+
+func (OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (OSFS) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+func (OSFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (OSFS) Readlink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
+func (OSFS) Open(name string) (File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (OSFS) SameFile(fi1, fi2 fs.FileInfo) bool {
+	return os.SameFile(fi1, fi2)
+}